@@ -0,0 +1,267 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+// Package bundle packs everything needed to deploy on disconnected clusters:
+// the rendered manifests, the list of the images they use and, optionally,
+// the images themselves as an OCI image layout.
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/registry"
+)
+
+const (
+	MetadataFileName = "metadata.json"
+	ImagesFileName   = "images.json"
+	ManifestsDir     = "manifests"
+	// ImagesDir holds the optional OCI image layout
+	ImagesDir = "images"
+)
+
+type Metadata struct {
+	Platform        string `json:"platform"`
+	PlatformVersion string `json:"platformVersion"`
+	UpdaterType     string `json:"updaterType"`
+	// Components are listed in the order they must be deployed
+	Components []string `json:"components"`
+}
+
+type Bundle struct {
+	Metadata   Metadata
+	Components []manifests.Component
+	Images     images.Output
+}
+
+// Write writes the bundle in dir: the metadata, one manifest file per component and the images.
+// The OCI image layout, if any, must be written separately in ImagesDir.
+func Write(dir string, bd Bundle) error {
+	if err := os.MkdirAll(filepath.Join(dir, ManifestsDir), 0755); err != nil {
+		return err
+	}
+	md := bd.Metadata
+	md.Components = nil
+	for _, comp := range bd.Components {
+		var buf bytes.Buffer
+		if err := manifests.RenderObjects(comp.Objects, &buf); err != nil {
+			return fmt.Errorf("cannot render component %q: %w", comp.Name, err)
+		}
+		if err := os.WriteFile(componentPath(dir, comp.Name), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		md.Components = append(md.Components, comp.Name)
+	}
+	if err := writeJSON(filepath.Join(dir, MetadataFileName), md); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, ImagesFileName), bd.Images)
+}
+
+// Load reads the bundle written by Write in dir.
+func Load(dir string) (Bundle, error) {
+	var bd Bundle
+	if err := readJSON(filepath.Join(dir, MetadataFileName), &bd.Metadata); err != nil {
+		return bd, err
+	}
+	if err := readJSON(filepath.Join(dir, ImagesFileName), &bd.Images); err != nil {
+		return bd, err
+	}
+	for _, name := range bd.Metadata.Components {
+		objs, err := loadObjects(componentPath(dir, name))
+		if err != nil {
+			return bd, fmt.Errorf("cannot load component %q: %w", name, err)
+		}
+		bd.Components = append(bd.Components, manifests.Component{Name: name, Objects: objs})
+	}
+	return bd, nil
+}
+
+// HasImages tells if the bundle in dir includes the OCI image layout.
+func HasImages(dir string) bool {
+	return registry.IsLayout(filepath.Join(dir, ImagesDir))
+}
+
+// Archive writes the content of dir as a gzipped tarball.
+func Archive(dir string, w io.Writer) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	err := filepath.WalkDir(dir, func(fpath string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, fpath)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if de.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		fh, err := os.Open(fpath)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		_, err = io.Copy(tw, fh)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// Extract unpacks in dir a tarball written by Archive. Only regular files and directories are allowed.
+func Extract(r io.Reader, dir string) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in bundle: %q", hdr.Name)
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, dst); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry in bundle: %q", hdr.Name)
+		}
+	}
+}
+
+func extractFile(r io.Reader, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	fh, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fh, r)
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func loadObjects(path string) ([]client.Object, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	var objs []client.Object
+	rd := k8syaml.NewYAMLReader(bufio.NewReader(fh))
+	for {
+		data, err := rd.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		obj, err := manifests.DeserializeObjectFromData(data)
+		if err != nil {
+			return nil, err
+		}
+		cObj, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("unsupported object %s", obj.GetObjectKind().GroupVersionKind())
+		}
+		objs = append(objs, cObj)
+	}
+}
+
+func componentPath(dir, name string) string {
+	return filepath.Join(dir, ManifestsDir, name+".yaml")
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %q: %w", filepath.Base(path), err)
+	}
+	return nil
+}
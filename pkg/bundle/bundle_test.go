@@ -0,0 +1,153 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+func TestRoundTrip(t *testing.T) {
+	bd := Bundle{
+		Metadata: Metadata{
+			Platform:        "kubernetes",
+			PlatformVersion: "v1.26",
+			UpdaterType:     "rte",
+		},
+		Components: []manifests.Component{
+			{
+				Name: "api",
+				Objects: []client.Object{
+					&corev1.Namespace{
+						TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+						ObjectMeta: metav1.ObjectMeta{Name: "tas"},
+					},
+				},
+			},
+			{
+				Name: "scheduler-plugin",
+				Objects: []client.Object{
+					&corev1.ServiceAccount{
+						TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+						ObjectMeta: metav1.ObjectMeta{Name: "sched", Namespace: "tas"},
+					},
+					&appsv1.Deployment{
+						TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+						ObjectMeta: metav1.ObjectMeta{Name: "sched", Namespace: "tas"},
+					},
+				},
+			},
+		},
+		Images: images.Output{
+			SchedulerPlugin: "registry.k8s.io/sched:v1",
+			Sources:         map[string]images.Source{"scheduler_plugin": images.SourceDefault},
+		},
+	}
+
+	srcDir := t.TempDir()
+	if err := Write(srcDir, bd); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Archive(srcDir, &buf); err != nil {
+		t.Fatalf("archive failed: %v", err)
+	}
+	dstDir := t.TempDir()
+	if err := Extract(&buf, dstDir); err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+	got, err := Load(dstDir)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Metadata.Components, []string{"api", "scheduler-plugin"}) {
+		t.Errorf("unexpected components: %v", got.Metadata.Components)
+	}
+	if got.Metadata.Platform != bd.Metadata.Platform || got.Metadata.PlatformVersion != bd.Metadata.PlatformVersion {
+		t.Errorf("unexpected metadata: %+v", got.Metadata)
+	}
+	if !reflect.DeepEqual(got.Images, bd.Images) {
+		t.Errorf("unexpected images: %+v", got.Images)
+	}
+	for idx, comp := range got.Components {
+		expected := bd.Components[idx]
+		if len(comp.Objects) != len(expected.Objects) {
+			t.Fatalf("component %q: got %d objects expected %d", comp.Name, len(comp.Objects), len(expected.Objects))
+		}
+		for oi, obj := range comp.Objects {
+			if reflect.TypeOf(obj) != reflect.TypeOf(expected.Objects[oi]) || obj.GetName() != expected.Objects[oi].GetName() {
+				t.Errorf("component %q: got %T %q expected %T %q", comp.Name, obj, obj.GetName(), expected.Objects[oi], expected.Objects[oi].GetName())
+			}
+		}
+	}
+	if HasImages(dstDir) {
+		t.Errorf("bundle without images reports images")
+	}
+}
+
+func TestExtractRejectsUnsafeEntries(t *testing.T) {
+	type testCase struct {
+		name string
+		hdr  tar.Header
+	}
+
+	testCases := []testCase{
+		{
+			name: "parent path",
+			hdr:  tar.Header{Name: "../evil", Typeflag: tar.TypeReg},
+		},
+		{
+			name: "absolute path",
+			hdr:  tar.Header{Name: "/tmp/evil", Typeflag: tar.TypeReg},
+		},
+		{
+			name: "symlink",
+			hdr:  tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gzw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gzw)
+			hdr := tc.hdr
+			if err := tw.WriteHeader(&hdr); err != nil {
+				t.Fatalf("cannot write the tarball: %v", err)
+			}
+			tw.Close()
+			gzw.Close()
+
+			if err := Extract(&buf, t.TempDir()); err == nil {
+				t.Errorf("unsafe entry %q extracted", tc.hdr.Name)
+			}
+		})
+	}
+}
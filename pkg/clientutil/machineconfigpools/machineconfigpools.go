@@ -0,0 +1,76 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package machineconfigpools
+
+import (
+	"fmt"
+
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+)
+
+// GetByMachineConfigLabels returns all the pools which would pick a MachineConfig with the specified labels
+func GetByMachineConfigLabels(env *deployer.Environment, mcLabels map[string]string) ([]machineconfigv1.MachineConfigPool, error) {
+	mcps := &machineconfigv1.MachineConfigPoolList{}
+	if err := env.Cli.List(env.Ctx, mcps); err != nil {
+		return nil, err
+	}
+	return FilterByMachineConfigLabels(mcps.Items, mcLabels)
+}
+
+// FilterByMachineConfigLabels returns the pools whose machine config selector matches the specified labels
+func FilterByMachineConfigLabels(mcps []machineconfigv1.MachineConfigPool, mcLabels map[string]string) ([]machineconfigv1.MachineConfigPool, error) {
+	var ret []machineconfigv1.MachineConfigPool
+	for _, mcp := range mcps {
+		if mcp.Spec.MachineConfigSelector == nil {
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(mcp.Spec.MachineConfigSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid machine config selector for pool %q: %w", mcp.Name, err)
+		}
+		if sel.Empty() || !sel.Matches(labels.Set(mcLabels)) {
+			continue
+		}
+		ret = append(ret, mcp)
+	}
+	return ret, nil
+}
+
+// FindByNode returns the first pool whose node selector matches the given node, if any
+func FindByNode(mcps []machineconfigv1.MachineConfigPool, node *corev1.Node) (*machineconfigv1.MachineConfigPool, error) {
+	for idx := range mcps {
+		mcp := &mcps[idx]
+		if mcp.Spec.NodeSelector == nil {
+			continue
+		}
+		sel, err := metav1.LabelSelectorAsSelector(mcp.Spec.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector for pool %q: %w", mcp.Name, err)
+		}
+		if sel.Empty() || !sel.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		return mcp, nil
+	}
+	return nil, nil
+}
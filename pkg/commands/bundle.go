@@ -0,0 +1,375 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	"github.com/spf13/cobra"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/bundle"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/updaters"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	apimf "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/api"
+	nfdmf "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/nfd"
+	rtemf "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/rte"
+	schedmf "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/sched"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectwait"
+	apiwait "github.com/k8stopologyawareschedwg/deployer/pkg/objectwait/api"
+	nfdwait "github.com/k8stopologyawareschedwg/deployer/pkg/objectwait/nfd"
+	rtewait "github.com/k8stopologyawareschedwg/deployer/pkg/objectwait/rte"
+	schedwait "github.com/k8stopologyawareschedwg/deployer/pkg/objectwait/sched"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/registry"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/signature"
+)
+
+type bundleOptions struct {
+	path           string
+	sourceRegistry string
+	targetRegistry string
+	pushImages     bool
+	plainHTTP      bool
+	imageCheck     imageCheckOptions
+}
+
+func NewBundleCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+	opts := &bundleOptions{}
+	bdl := &cobra.Command{
+		Use:   "bundle",
+		Short: "create and deploy bundles for disconnected clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ShowHelp(cmd, args)
+		},
+		Args: cobra.NoArgs,
+	}
+	bdl.PersistentFlags().StringVar(&opts.path, "bundle", "tas-bundle.tar.gz", "path of the bundle tarball.")
+	bdl.PersistentFlags().BoolVar(&opts.plainHTTP, "plain-http", false, "talk to the registries using plain HTTP, not HTTPS.")
+	bdl.AddCommand(NewBundleCreateCommand(env, commonOpts, opts))
+	bdl.AddCommand(NewBundleDeployCommand(env, commonOpts, opts))
+	return bdl
+}
+
+func NewBundleCreateCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *bundleOptions) *cobra.Command {
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "render the manifests for the given platform and pack them, with the images they use, in a bundle",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if commonOpts.UserPlatform == platform.Unknown {
+				return fmt.Errorf("must explicitly select a cluster platform")
+			}
+			if err := checkImages(env, commonOpts, &opts.imageCheck); err != nil {
+				return err
+			}
+			return createBundle(env, commonOpts, opts)
+		},
+		Args: cobra.NoArgs,
+	}
+	create.Flags().StringVar(&opts.sourceRegistry, "source-registry", "", "copy the images in the bundle, pulling them from this registry (host[:port][/path]), which must mirror the original repositories. If empty, only the image list is bundled.")
+	addImageCheckFlags(create.Flags(), &opts.imageCheck)
+	return create
+}
+
+func NewBundleDeployCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *bundleOptions) *cobra.Command {
+	deploy := &cobra.Command{
+		Use:   "deploy",
+		Short: "deploy the manifests of a bundle, pulling the images from an internal registry",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.pushImages && opts.targetRegistry == "" {
+				return fmt.Errorf("--push-images requires --registry")
+			}
+			return deployBundle(env, commonOpts, opts)
+		},
+		Args: cobra.NoArgs,
+	}
+	deploy.Flags().StringVar(&opts.targetRegistry, "registry", "", "rewrite all the images to be pulled from this registry (host[:port][/path]).")
+	deploy.Flags().BoolVar(&opts.pushImages, "push-images", false, "push the images copied in the bundle to the registry before deploying.")
+	deploy.Flags().BoolVarP(&commonOpts.WaitCompletion, "wait", "W", false, "wait for deployment to be all completed.")
+	deploy.Flags().BoolVar(&commonOpts.Resume, "resume", false, "resume an interrupted deployment, skipping the objects already present and matching the rendered ones.")
+	addImageVerifyFlags(deploy.Flags(), &opts.imageCheck)
+	return deploy
+}
+
+func createBundle(env *deployer.Environment, commonOpts *deploy.Options, opts *bundleOptions) error {
+	comps, err := RenderComponents(env, commonOpts)
+	if err != nil {
+		return err
+	}
+	imo := images.NewOutput(commonOpts.Images, getUpdaterImageKey(commonOpts.UpdaterType))
+
+	dir, err := os.MkdirTemp("", "tas-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	err = bundle.Write(dir, bundle.Bundle{
+		Metadata: bundle.Metadata{
+			Platform:        commonOpts.UserPlatform.String(),
+			PlatformVersion: commonOpts.UserPlatformVersion.String(),
+			UpdaterType:     commonOpts.UpdaterType,
+		},
+		Components: comps,
+		Images:     imo,
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.sourceRegistry != "" {
+		if err := pullBundleImages(env, opts, imo.ToList(), filepath.Join(dir, bundle.ImagesDir)); err != nil {
+			return err
+		}
+	}
+
+	fh, err := os.Create(opts.path)
+	if err != nil {
+		return err
+	}
+	err = bundle.Archive(dir, fh)
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	env.Log.Info("bundle created", "path", opts.path, "platform", commonOpts.UserPlatform, "version", commonOpts.UserPlatformVersion, "withImages", opts.sourceRegistry != "")
+	return nil
+}
+
+func pullBundleImages(env *deployer.Environment, opts *bundleOptions, imageList []string, layoutDir string) error {
+	ly, err := registry.NewLayout(layoutDir)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, image := range imageList {
+		if image == "" || seen[image] {
+			continue
+		}
+		seen[image] = true
+
+		host, repo, ref := images.SplitImage(images.MirrorImage(image, opts.sourceRegistry))
+		reg := registry.Registry{Host: host, PlainHTTP: opts.plainHTTP}
+		desc, err := reg.Pull(env.Ctx, repo, ref, ly)
+		if err != nil {
+			return fmt.Errorf("cannot copy image %q: %w", image, err)
+		}
+		ly.AddImage(image, desc)
+		env.Log.Info("image copied", "image", image, "digest", desc.Digest)
+
+		// copy the signatures too, if any, to verify the images offline
+		sigTag := signature.SignatureTag(desc.Digest)
+		sigDesc, err := reg.Pull(env.Ctx, repo, sigTag, ly)
+		if errors.Is(err, registry.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot copy the signatures of image %q: %w", image, err)
+		}
+		ly.AddImage(images.Repository(image)+":"+sigTag, sigDesc)
+		env.Log.Info("image signatures copied", "image", image, "digest", sigDesc.Digest)
+	}
+	return ly.Save()
+}
+
+func deployBundle(env *deployer.Environment, commonOpts *deploy.Options, opts *bundleOptions) error {
+	dir, err := os.MkdirTemp("", "tas-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	fh, err := os.Open(opts.path)
+	if err != nil {
+		return err
+	}
+	err = bundle.Extract(fh, dir)
+	fh.Close()
+	if err != nil {
+		return fmt.Errorf("cannot extract bundle %q: %w", opts.path, err)
+	}
+	bd, err := bundle.Load(dir)
+	if err != nil {
+		return fmt.Errorf("cannot load bundle %q: %w", opts.path, err)
+	}
+	if commonOpts.UserPlatform != platform.Unknown && commonOpts.UserPlatform.String() != bd.Metadata.Platform {
+		return fmt.Errorf("bundle rendered for platform %q, requested %q", bd.Metadata.Platform, commonOpts.UserPlatform)
+	}
+	env.Log.Info("bundle loaded", "path", opts.path, "platform", bd.Metadata.Platform, "version", bd.Metadata.PlatformVersion, "updater", bd.Metadata.UpdaterType)
+
+	digests, err := verifyBundleImages(env, opts, bd, dir)
+	if err != nil {
+		return err
+	}
+
+	if opts.pushImages {
+		if !bundle.HasImages(dir) {
+			return fmt.Errorf("bundle %q doesn't include the images", opts.path)
+		}
+		if err := pushBundleImages(env, opts, filepath.Join(dir, bundle.ImagesDir)); err != nil {
+			return err
+		}
+	}
+
+	if err := env.EnsureClient(); err != nil {
+		return err
+	}
+	for _, comp := range bd.Components {
+		objectupdate.PinImages(comp.Objects, digests)
+		objectupdate.SetRegistryMirror(comp.Objects, opts.targetRegistry)
+		waits := creatableWaits(env, bd.Metadata.UpdaterType, comp, commonOpts.WaitCompletion)
+		for _, obj := range comp.Objects {
+			if err := env.CreateOrResumeObject(obj, commonOpts.Resume); err != nil {
+				return fmt.Errorf("cannot deploy component %q: %w", comp.Name, err)
+			}
+			wait, ok := waits[obj]
+			if !ok {
+				continue
+			}
+			if err := wait(env.Ctx); err != nil {
+				return fmt.Errorf("cannot deploy component %q: %w", comp.Name, err)
+			}
+		}
+		env.Log.Info("component deployed", "component", comp.Name)
+	}
+	return nil
+}
+
+// creatableWaits returns the waits to do after creating the objects of the component, the same the
+// deployer does, taken from the objectwait lists. Besides the API ones, they are done only if waitCompletion.
+func creatableWaits(env *deployer.Environment, updaterType string, comp manifests.Component, waitCompletion bool) map[client.Object]func(context.Context) error {
+	// the manifests hold only the objects the waits need
+	var wos []objectwait.WaitableObject
+	switch {
+	case comp.Name == ComponentAPI:
+		mf := apimf.Manifests{}
+		for _, obj := range comp.Objects {
+			if crd, ok := obj.(*apiextensionv1.CustomResourceDefinition); ok {
+				mf.Crd = crd
+			}
+		}
+		wos = apiwait.Creatable(mf, env.Cli, env.Log)
+	case !waitCompletion:
+		return nil
+	case comp.Name == ComponentSchedulerPlugin:
+		mf := schedmf.Manifests{}
+		mf.DPScheduler, mf.DPController = schedulerDeployments(comp.Objects)
+		wos = schedwait.Creatable(mf, env.Cli, env.Log)
+	case comp.Name == ComponentTopologyUpdater && updaterType == updaters.RTE:
+		mf := rtemf.Manifests{}
+		for _, obj := range comp.Objects {
+			switch o := obj.(type) {
+			case *appsv1.DaemonSet:
+				mf.DaemonSet = o
+			case *machineconfigv1.MachineConfig:
+				mf.MachineConfig = o
+			}
+		}
+		if mf.DaemonSet != nil {
+			wos = rtewait.Creatable(mf, env.Cli, env.Log)
+		}
+	case comp.Name == ComponentTopologyUpdater:
+		mf := nfdmf.Manifests{}
+		for _, obj := range comp.Objects {
+			if ds, ok := obj.(*appsv1.DaemonSet); ok {
+				mf.DSTopologyUpdater = ds
+			}
+		}
+		wos = nfdwait.Creatable(mf, env.Cli, env.Log)
+	}
+
+	waits := make(map[client.Object]func(context.Context) error)
+	for _, wo := range wos {
+		if wo.Wait != nil {
+			waits[wo.Obj] = wo.Wait
+		}
+	}
+	return waits
+}
+
+// schedulerDeployments finds the scheduler and the controller deployments by their containers,
+// because the bundle may list them in any order.
+func schedulerDeployments(objs []client.Object) (*appsv1.Deployment, *appsv1.Deployment) {
+	var dpScheduler, dpController *appsv1.Deployment
+	for _, obj := range objs {
+		dp, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			continue
+		}
+		conts := dp.Spec.Template.Spec.Containers
+		if objectupdate.FindContainerByName(conts, manifests.ContainerNameScheduler) != nil {
+			dpScheduler = dp
+		} else if objectupdate.FindContainerByName(conts, manifests.ContainerNameController) != nil {
+			dpController = dp
+		}
+	}
+	return dpScheduler, dpController
+}
+
+// verifyBundleImages verifies the signatures of the bundle images, if requested, and returns their digests.
+// Unless told otherwise, the images copied in the bundle, if any, are verified.
+func verifyBundleImages(env *deployer.Environment, opts *bundleOptions, bd bundle.Bundle, dir string) (map[string]string, error) {
+	check := opts.imageCheck
+	if check.verifyKeyFile != "" && check.verifyRegistry == "" && check.verifyLayout == "" && bundle.HasImages(dir) {
+		check.verifyLayout = filepath.Join(dir, bundle.ImagesDir)
+	}
+	if err := validateImageVerifyOptions(&check); err != nil {
+		return nil, err
+	}
+	if check.verifyKeyFile == "" {
+		return nil, nil
+	}
+	var imageList []string
+	for _, image := range bd.Images.ToList() {
+		if image != "" {
+			imageList = append(imageList, image)
+		}
+	}
+	return verifyImageSignatures(env, &check, imageList)
+}
+
+func pushBundleImages(env *deployer.Environment, opts *bundleOptions, layoutDir string) error {
+	ly, err := registry.OpenLayout(layoutDir)
+	if err != nil {
+		return err
+	}
+	for _, desc := range ly.Images() {
+		image := desc.Annotations[registry.AnnotationRefName]
+		host, repo, ref := images.SplitImage(images.MirrorImage(image, opts.targetRegistry))
+		reg := registry.Registry{Host: host, PlainHTTP: opts.plainHTTP}
+		if err := reg.Push(env.Ctx, ly, desc, repo, ref); err != nil {
+			return fmt.Errorf("cannot push image %q: %w", image, err)
+		}
+		env.Log.Info("image pushed", "image", image, "registry", opts.targetRegistry)
+	}
+	return nil
+}
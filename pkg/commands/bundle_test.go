@@ -0,0 +1,167 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/updaters"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+func TestCreatableWaits(t *testing.T) {
+	crd := &apiextensionv1.CustomResourceDefinition{}
+	dpScheduler := newContainerDeployment(manifests.ContainerNameScheduler)
+	dpController := newContainerDeployment(manifests.ContainerNameController)
+	ds := &appsv1.DaemonSet{}
+	mc := &machineconfigv1.MachineConfig{}
+	cm := &corev1.ConfigMap{}
+
+	type testCase struct {
+		name           string
+		updaterType    string
+		comp           manifests.Component
+		waitCompletion bool
+		expected       []client.Object
+	}
+
+	testCases := []testCase{
+		{
+			name:     "api always waits",
+			comp:     manifests.Component{Name: ComponentAPI, Objects: []client.Object{crd}},
+			expected: []client.Object{crd},
+		},
+		{
+			name: "scheduler without wait",
+			comp: manifests.Component{Name: ComponentSchedulerPlugin, Objects: []client.Object{cm, dpScheduler, dpController}},
+		},
+		{
+			name:           "scheduler",
+			comp:           manifests.Component{Name: ComponentSchedulerPlugin, Objects: []client.Object{cm, dpScheduler, dpController}},
+			waitCompletion: true,
+			expected:       []client.Object{dpScheduler, dpController},
+		},
+		{
+			name:           "scheduler in reverse order",
+			comp:           manifests.Component{Name: ComponentSchedulerPlugin, Objects: []client.Object{dpController, cm, dpScheduler}},
+			waitCompletion: true,
+			expected:       []client.Object{dpScheduler, dpController},
+		},
+		{
+			name:           "rte",
+			updaterType:    updaters.RTE,
+			comp:           manifests.Component{Name: ComponentTopologyUpdater, Objects: []client.Object{mc, cm, ds}},
+			waitCompletion: true,
+			expected:       []client.Object{mc, ds},
+		},
+		{
+			name:           "nfd",
+			updaterType:    updaters.NFD,
+			comp:           manifests.Component{Name: ComponentTopologyUpdater, Objects: []client.Object{cm, ds}},
+			waitCompletion: true,
+			expected:       []client.Object{ds},
+		},
+	}
+
+	env := &deployer.Environment{Log: logr.Discard()}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			waits := creatableWaits(env, tc.updaterType, tc.comp, tc.waitCompletion)
+			if len(waits) != len(tc.expected) {
+				t.Errorf("got %d waits expected %d", len(waits), len(tc.expected))
+			}
+			for _, obj := range tc.expected {
+				if _, ok := waits[obj]; !ok {
+					t.Errorf("missing wait for %T", obj)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerDeployments(t *testing.T) {
+	dpScheduler := newContainerDeployment(manifests.ContainerNameScheduler)
+	dpController := newContainerDeployment(manifests.ContainerNameController)
+	cm := &corev1.ConfigMap{}
+
+	type testCase struct {
+		name               string
+		objs               []client.Object
+		expectedScheduler  *appsv1.Deployment
+		expectedController *appsv1.Deployment
+	}
+
+	testCases := []testCase{
+		{
+			name:               "rendered order",
+			objs:               []client.Object{cm, dpScheduler, dpController},
+			expectedScheduler:  dpScheduler,
+			expectedController: dpController,
+		},
+		{
+			name:               "reverse order",
+			objs:               []client.Object{dpController, cm, dpScheduler},
+			expectedScheduler:  dpScheduler,
+			expectedController: dpController,
+		},
+		{
+			name:               "controller only",
+			objs:               []client.Object{cm, dpController},
+			expectedController: dpController,
+		},
+		{
+			name: "unknown deployment",
+			objs: []client.Object{newContainerDeployment("foo")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotScheduler, gotController := schedulerDeployments(tc.objs)
+			if gotScheduler != tc.expectedScheduler {
+				t.Errorf("got scheduler deployment %s expected %s", deploymentName(gotScheduler), deploymentName(tc.expectedScheduler))
+			}
+			if gotController != tc.expectedController {
+				t.Errorf("got controller deployment %s expected %s", deploymentName(gotController), deploymentName(tc.expectedController))
+			}
+		})
+	}
+}
+
+func newContainerDeployment(containerName string) *appsv1.Deployment {
+	dp := &appsv1.Deployment{}
+	dp.Name = containerName
+	dp.Spec.Template.Spec.Containers = []corev1.Container{{Name: containerName}}
+	return dp
+}
+
+func deploymentName(dp *appsv1.Deployment) string {
+	if dp == nil {
+		return "<nil>"
+	}
+	return dp.Name
+}
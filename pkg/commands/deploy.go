@@ -31,18 +31,24 @@ import (
 )
 
 func NewDeployCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+	checkOpts := &imageCheckOptions{}
 	deploy := &cobra.Command{
 		Use:   "deploy",
 		Short: "deploy the components and configurations needed for topology-aware-scheduling",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkImages(env, commonOpts, checkOpts); err != nil {
+				return err
+			}
 			return deploy.OnCluster(env, commonOpts)
 		},
 		Args: cobra.NoArgs,
 	}
 	deploy.PersistentFlags().BoolVarP(&commonOpts.WaitCompletion, "wait", "W", false, "wait for deployment to be all completed.")
+	deploy.PersistentFlags().BoolVar(&commonOpts.Resume, "resume", false, "resume an interrupted deployment, skipping the objects already present and matching the rendered ones.")
+	addImageCheckFlags(deploy.PersistentFlags(), checkOpts)
 	deploy.AddCommand(NewDeployAPICommand(env, commonOpts))
-	deploy.AddCommand(NewDeploySchedulerPluginCommand(env, commonOpts))
-	deploy.AddCommand(NewDeployTopologyUpdaterCommand(env, commonOpts))
+	deploy.AddCommand(NewDeploySchedulerPluginCommand(env, commonOpts, checkOpts))
+	deploy.AddCommand(NewDeployTopologyUpdaterCommand(env, commonOpts, checkOpts))
 	return deploy
 }
 
@@ -67,7 +73,7 @@ func NewDeployAPICommand(env *deployer.Environment, commonOpts *deploy.Options)
 			}
 
 			env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
-			if err := api.Deploy(env, api.Options{Platform: commonOpts.ClusterPlatform}); err != nil {
+			if err := api.Deploy(env, deploy.APIOptionsFrom(commonOpts)); err != nil {
 				return err
 			}
 			return nil
@@ -77,13 +83,16 @@ func NewDeployAPICommand(env *deployer.Environment, commonOpts *deploy.Options)
 	return deploy
 }
 
-func NewDeploySchedulerPluginCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+func NewDeploySchedulerPluginCommand(env *deployer.Environment, commonOpts *deploy.Options, checkOpts *imageCheckOptions) *cobra.Command {
 	deploy := &cobra.Command{
 		Use:   "scheduler-plugin",
 		Short: "deploy the scheduler plugin needed for topology-aware-scheduling",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 
+			if err = checkImages(env, commonOpts, checkOpts); err != nil {
+				return err
+			}
 			if err = env.EnsureClient(); err != nil {
 				return err
 			}
@@ -100,30 +109,23 @@ func NewDeploySchedulerPluginCommand(env *deployer.Environment, commonOpts *depl
 			}
 
 			env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
-			return sched.Deploy(env, sched.Options{
-				Platform:          commonOpts.ClusterPlatform,
-				WaitCompletion:    commonOpts.WaitCompletion,
-				Replicas:          int32(commonOpts.Replicas),
-				RTEConfigData:     commonOpts.RTEConfigData,
-				PullIfNotPresent:  commonOpts.PullIfNotPresent,
-				ProfileName:       commonOpts.SchedProfileName,
-				CacheResyncPeriod: commonOpts.SchedResyncPeriod,
-				CtrlPlaneAffinity: commonOpts.SchedCtrlPlaneAffinity,
-				Verbose:           commonOpts.SchedVerbose,
-			})
+			return sched.Deploy(env, deploy.SchedOptionsFrom(commonOpts))
 		},
 		Args: cobra.NoArgs,
 	}
 	return deploy
 }
 
-func NewDeployTopologyUpdaterCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+func NewDeployTopologyUpdaterCommand(env *deployer.Environment, commonOpts *deploy.Options, checkOpts *imageCheckOptions) *cobra.Command {
 	deploy := &cobra.Command{
 		Use:   "topology-updater",
 		Short: "deploy the topology updater needed for topology-aware-scheduling",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var err error
 
+			if err = checkImages(env, commonOpts, checkOpts); err != nil {
+				return err
+			}
 			if err = env.EnsureClient(); err != nil {
 				return err
 			}
@@ -141,12 +143,17 @@ func NewDeployTopologyUpdaterCommand(env *deployer.Environment, commonOpts *depl
 
 			env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
 			return updaters.Deploy(env, commonOpts.UpdaterType, updaters.Options{
-				Platform:        commonOpts.ClusterPlatform,
-				PlatformVersion: commonOpts.ClusterVersion,
-				WaitCompletion:  commonOpts.WaitCompletion,
-				RTEConfigData:   commonOpts.RTEConfigData,
-				DaemonSet:       deploy.DaemonSetOptionsFrom(commonOpts),
-				EnableCRIHooks:  commonOpts.UpdaterCRIHooksEnable,
+				Platform:                  commonOpts.ClusterPlatform,
+				PlatformVersion:           commonOpts.ClusterVersion,
+				WaitCompletion:            commonOpts.WaitCompletion,
+				RTEConfigData:             commonOpts.RTEConfigData,
+				DaemonSet:                 deploy.DaemonSetOptionsFrom(commonOpts),
+				EnableCRIHooks:            commonOpts.UpdaterCRIHooksEnable,
+				MachineConfigPoolSelector: commonOpts.MachineConfigPoolSelector,
+				Namespace:                 commonOpts.UpdaterNamespace,
+				Metadata:                  deploy.MetadataOptionsFrom(commonOpts),
+				ImagePull:                 deploy.ImagePullOptionsFrom(commonOpts),
+				Resume:                    commonOpts.Resume,
 			})
 		},
 		Args: cobra.NoArgs,
@@ -0,0 +1,192 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/updaters"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/helm"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+const (
+	HelmChartName    = "topology-aware-scheduling"
+	HelmChartVersion = "0.1.0"
+)
+
+// the knobs are rendered with these values, which are then replaced by references to the chart values.
+// Numbers are chosen to be unlikely to appear anywhere else in the manifests.
+const (
+	helmSentinelReplicas        = 918273641
+	helmSentinelResyncSeconds   = 918273642
+	helmSentinelSchedVerbose    = 918273643
+	helmSentinelUpdaterVerbose  = 918273644
+	helmSentinelSyncPeriod      = 918273645 * time.Millisecond
+	helmSentinelProfileName     = "helm-sentinel-profile-name"
+	helmSentinelSchedImage      = "helm-sentinel-scheduler-image"
+	helmSentinelControllerImage = "helm-sentinel-controller-image"
+	helmSentinelRTEImage        = "helm-sentinel-rte-image"
+	helmSentinelNFDImage        = "helm-sentinel-nfd-image"
+	helmSentinelPauseImage      = "helm-sentinel-pause-image"
+)
+
+const (
+	helmKeyUpdaterType       = "updater.type"
+	helmKeyUpdaterNotif      = "updater.notifications"
+	helmKeySchedCtrlAffinity = "scheduler.ctrlPlaneAffinity"
+	helmKeySchedPDB          = "scheduler.podDisruptionBudget"
+	helmKeySchedLeaderElect  = "scheduler.leaderElection"
+)
+
+// writeHelmChart renders the manifests through the same code path of the other formats,
+// with the knobs set to sentinel values, and lifts them into the chart values.
+func writeHelmChart(env *deployer.Environment, commonOpts *deploy.Options, outputDir string) error {
+	chart := helm.Chart{
+		APIVersion:  helm.ChartAPIVersionV2,
+		Name:        HelmChartName,
+		Description: "topology-aware-scheduling components for " + commonOpts.UserPlatform.String(),
+		Type:        "application",
+		Version:     HelmChartVersion,
+	}
+
+	imgs := commonOpts.Images.WithDefaults()
+	schedSentinel := helmSchedulerSentinel(imgs.Scheduler)
+	gen := helm.Generator{
+		Axes: []helm.Axis{
+			{Key: helmKeyUpdaterType, Choices: []interface{}{updaters.RTE, updaters.NFD}},
+			{Key: helmKeyUpdaterNotif, Choices: []interface{}{true, false}},
+			{Key: helmKeySchedCtrlAffinity, Choices: []interface{}{true, false}},
+			// more than one replica always needs the PDB and the leader election, like on the command line
+			{Key: helmKeySchedPDB, Choices: []interface{}{true, false}, Expr: helmMoreReplicasOr(helmKeySchedPDB)},
+			{Key: helmKeySchedLeaderElect, Choices: []interface{}{true, false}, Expr: helmMoreReplicasOr(helmKeySchedLeaderElect)},
+		},
+		Placeholders: []helm.Placeholder{
+			{Key: "scheduler.replicas", Sentinel: strconv.Itoa(helmSentinelReplicas)},
+			{Key: "scheduler.profileName", Sentinel: helmSentinelProfileName},
+			{Key: "scheduler.cacheResyncPeriodSeconds", Sentinel: strconv.Itoa(helmSentinelResyncSeconds), Optional: true},
+			{Key: "scheduler.verbose", Sentinel: strconv.Itoa(helmSentinelSchedVerbose)},
+			{Key: "updater.syncPeriod", Sentinel: helmSentinelSyncPeriod.String(), Optional: true},
+			{Key: "updater.verbose", Sentinel: strconv.Itoa(helmSentinelUpdaterVerbose)},
+			{Key: "updater.podsFingerprint", Prefix: "--pods-fingerprint=", Sentinel: "true"},
+			{Key: "images.scheduler", Sentinel: schedSentinel},
+			{Key: "images.controller", Sentinel: helmSentinelControllerImage},
+			{Key: "images.resourceTopologyExporter", Sentinel: helmSentinelRTEImage},
+			{Key: "images.nodeFeatureDiscovery", Sentinel: helmSentinelNFDImage},
+			{Key: "images.pause", Sentinel: helmSentinelPauseImage},
+		},
+		Render: func(comb helm.Combination) ([]manifests.Component, error) {
+			opts := *commonOpts
+			opts.UpdaterType = comb[helmKeyUpdaterType].(string)
+			opts.UpdaterNotifEnable = comb[helmKeyUpdaterNotif].(bool)
+			opts.SchedCtrlPlaneAffinity = comb[helmKeySchedCtrlAffinity].(bool)
+			opts.SchedPodDisruptionBudget = comb[helmKeySchedPDB].(bool)
+			opts.SchedLeaderElection = comb[helmKeySchedLeaderElect].(bool)
+			// the HA defaults must follow only the axes: render a single replica, then lift it to the value
+			opts.Replicas = 1
+			opts.SchedProfileName = helmSentinelProfileName
+			opts.SchedResyncPeriod = helmSentinelResyncSeconds * time.Second
+			opts.SchedVerbose = helmSentinelSchedVerbose
+			opts.UpdaterSyncPeriod = helmSentinelSyncPeriod
+			opts.UpdaterVerbose = helmSentinelUpdaterVerbose
+			opts.UpdaterPFPEnable = true
+			opts.Images = images.ImageSet{
+				Scheduler:                schedSentinel,
+				Controller:               helmSentinelControllerImage,
+				ResourceTopologyExporter: helmSentinelRTEImage,
+				NodeFeatureDiscovery:     helmSentinelNFDImage,
+				Pause:                    helmSentinelPauseImage,
+			}
+			comps, err := RenderComponents(env, &opts)
+			if err != nil {
+				return nil, err
+			}
+			setHelmSentinelReplicas(comps)
+			return comps, nil
+		},
+	}
+
+	imageValues := make(map[string]interface{})
+	for _, key := range images.Keys() {
+		imageValues[key], _ = imgs.Get(key)
+	}
+
+	values := map[string]interface{}{
+		"images": imageValues,
+		"scheduler": map[string]interface{}{
+			"replicas":                 commonOpts.Replicas,
+			"profileName":              commonOpts.SchedProfileName,
+			"cacheResyncPeriodSeconds": int64(commonOpts.SchedResyncPeriod.Seconds()),
+			"verbose":                  commonOpts.SchedVerbose,
+			"ctrlPlaneAffinity":        commonOpts.SchedCtrlPlaneAffinity,
+			"podDisruptionBudget":      commonOpts.SchedPodDisruptionBudget,
+			"leaderElection":           commonOpts.SchedLeaderElection,
+		},
+		"updater": map[string]interface{}{
+			"type":            commonOpts.UpdaterType,
+			"syncPeriod":      helmDuration(commonOpts.UpdaterSyncPeriod),
+			"verbose":         commonOpts.UpdaterVerbose,
+			"podsFingerprint": commonOpts.UpdaterPFPEnable,
+			"notifications":   commonOpts.UpdaterNotifEnable,
+		},
+	}
+
+	return helm.WriteChart(outputDir, chart, values, gen)
+}
+
+// helmSchedulerSentinel keeps the tag of the scheduler image, which selects the scheduler configuration API version.
+func helmSchedulerSentinel(image string) string {
+	if strings.Contains(image, "@") {
+		return helmSentinelSchedImage
+	}
+	_, _, tag := images.SplitImage(image)
+	return helmSentinelSchedImage + ":" + tag
+}
+
+func helmMoreReplicasOr(key string) string {
+	return fmt.Sprintf("or .Values.%s (gt (int .Values.scheduler.replicas) 1)", key)
+}
+
+func setHelmSentinelReplicas(comps []manifests.Component) {
+	for _, comp := range comps {
+		if comp.Name != ComponentSchedulerPlugin {
+			continue
+		}
+		for _, obj := range comp.Objects {
+			if dp, ok := obj.(*appsv1.Deployment); ok {
+				replicas := int32(helmSentinelReplicas)
+				dp.Spec.Replicas = &replicas
+			}
+		}
+	}
+}
+
+// empty value disables the periodic sync, like a zero duration does on the command line
+func helmDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,96 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+)
+
+func TestWriteHelmChartSchedulerConfigAPIVersion(t *testing.T) {
+	type testCase struct {
+		name       string
+		args       []string
+		apiVersion string
+	}
+
+	testCases := []testCase{
+		{
+			name:       "older scheduler image",
+			args:       []string{"--platform=kubernetes:v1.25", "--image=scheduler=quay.io/example/scheduler:v0.24.9"},
+			apiVersion: "kubescheduler.config.k8s.io/v1beta3",
+		},
+		{
+			name:       "newer scheduler image",
+			args:       []string{"--platform=kubernetes:v1.25", "--image=scheduler=quay.io/example/scheduler:v0.26.7"},
+			apiVersion: "kubescheduler.config.k8s.io/v1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := &deployer.Environment{Ctx: context.Background(), Log: logr.Discard()}
+			commonOpts := &deploy.Options{}
+			internalOpts := &internalOptions{}
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			InitFlags(flags, commonOpts, internalOpts)
+			if err := flags.Parse(tc.args); err != nil {
+				t.Fatalf("cannot parse the flags: %v", err)
+			}
+			if err := PostSetupOptions(env, commonOpts, internalOpts); err != nil {
+				t.Fatalf("cannot setup the options: %v", err)
+			}
+
+			dir := t.TempDir()
+			if err := writeHelmChart(env, commonOpts, dir); err != nil {
+				t.Fatalf("cannot write the helm chart: %v", err)
+			}
+
+			var chart strings.Builder
+			err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				chart.Write(data)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("cannot read the helm chart: %v", err)
+			}
+			if !strings.Contains(chart.String(), "apiVersion: "+tc.apiVersion+"\n") {
+				t.Errorf("the scheduler configuration does not use %q", tc.apiVersion)
+			}
+			if strings.Contains(chart.String(), "helm-sentinel") {
+				t.Errorf("the helm chart contains sentinel values")
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/updaters"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/registry"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/signature"
+)
+
+// imageCheckOptions control how the commands rendering or deploying the images check them first
+type imageCheckOptions struct {
+	// resolveDigests pins all the images to their current digests, querying
+	// resolveRegistry if set, or the registry of each image.
+	resolveDigests  bool
+	resolveRegistry string
+	// image signatures verification
+	verifyKeyFile  string
+	verifyRegistry string
+	verifyLayout   string
+}
+
+func addImageCheckFlags(flags *pflag.FlagSet, opts *imageCheckOptions) {
+	flags.BoolVar(&opts.resolveDigests, "resolve-digests", false, "pin all the images to the digests their tags point to, querying the registries. The original references are recorded as pod annotations.")
+	flags.StringVar(&opts.resolveRegistry, "resolve-digests-registry", "", "resolve the digests querying this registry, which mirrors the original repositories, instead of the registry of each image. Prefix with http:// to use plain HTTP (example: 'http://localhost:5000').")
+	addImageVerifyFlags(flags, opts)
+}
+
+func addImageVerifyFlags(flags *pflag.FlagSet, opts *imageCheckOptions) {
+	flags.StringVar(&opts.verifyKeyFile, "verify-signatures-key", "", "PEM public key to verify the cosign signatures of the images to deploy with, failing if any is unsigned or mismatched, and pinning them to the verified digests. Requires --verify-signatures-registry or --verify-signatures-layout.")
+	flags.StringVar(&opts.verifyRegistry, "verify-signatures-registry", "", "local registry, mirroring the original repositories, to read the images and their signatures from. Prefix with http:// to use plain HTTP.")
+	flags.StringVar(&opts.verifyLayout, "verify-signatures-layout", "", "OCI layout directory to read the images and their signatures from, like the images directory of a bundle.")
+}
+
+func validateImageVerifyOptions(opts *imageCheckOptions) error {
+	if opts.verifyKeyFile == "" {
+		if opts.verifyRegistry != "" || opts.verifyLayout != "" {
+			return fmt.Errorf("verifying the image signatures requires --verify-signatures-key")
+		}
+		return nil
+	}
+	if (opts.verifyRegistry == "") == (opts.verifyLayout == "") {
+		return fmt.Errorf("verifying the image signatures requires exactly one of --verify-signatures-registry and --verify-signatures-layout")
+	}
+	return nil
+}
+
+// checkImages updates the images to render or deploy according to the options.
+func checkImages(env *deployer.Environment, commonOpts *deploy.Options, opts *imageCheckOptions) error {
+	if err := validateImageVerifyOptions(opts); err != nil {
+		return err
+	}
+	if opts.resolveDigests {
+		resolved, err := registry.ResolveImageSet(env.Ctx, env.Log, commonOpts.Images, opts.resolveRegistry)
+		if err != nil {
+			return err
+		}
+		commonOpts.Images = resolved
+	}
+	if opts.verifyKeyFile == "" {
+		return nil
+	}
+
+	imgs := commonOpts.Images.WithDefaults()
+	keys := []string{images.KeyScheduler, images.KeyController, getUpdaterImageKey(commonOpts.UpdaterType)}
+	if commonOpts.UpdaterType == updaters.RTE {
+		keys = append(keys, images.KeyPause)
+	}
+	var imageList []string
+	for _, key := range keys {
+		image, _ := imgs.Get(key)
+		imageList = append(imageList, image)
+	}
+	digests, err := verifyImageSignatures(env, opts, imageList)
+	if err != nil {
+		return err
+	}
+	// deploy exactly what was verified, even if the tags move later
+	for _, key := range keys {
+		image, _ := imgs.Get(key)
+		if err := imgs.Pin(key, digests[image]); err != nil {
+			return err
+		}
+	}
+	commonOpts.Images = imgs
+	return nil
+}
+
+// verifyImageSignatures fails if any of the images is not signed with the given key,
+// otherwise returns the digests of the verified images.
+func verifyImageSignatures(env *deployer.Environment, opts *imageCheckOptions, imageList []string) (map[string]string, error) {
+	key, err := signature.LoadPublicKey(opts.verifyKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	src := signature.NewRegistrySource(opts.verifyRegistry)
+	if opts.verifyLayout != "" {
+		src, err = signature.NewLayoutSource(opts.verifyLayout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rep := signature.Verify(env.Ctx, src, key, imageList)
+	for _, res := range rep {
+		env.Log.Info("image signature", "image", res.Image, "digest", res.Digest, "status", res.Status)
+	}
+	if err := rep.Err(); err != nil {
+		return nil, err
+	}
+	digests := make(map[string]string, len(rep))
+	for _, res := range rep {
+		digests[res.Image] = res.Digest
+	}
+	return digests, nil
+}
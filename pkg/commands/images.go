@@ -30,7 +30,6 @@ import (
 type ImagesOptions struct {
 	jsonOutput bool
 	rawOutput  bool
-	useSHA     bool
 }
 
 func NewImagesCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
@@ -39,13 +38,11 @@ func NewImagesCommand(env *deployer.Environment, commonOpts *deploy.Options) *co
 		Use:   "images",
 		Short: "dump the container images used to deploy",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			images.SetDefaults(opts.useSHA)
-			updaterImage := getUpdaterImage(commonOpts.UpdaterType)
 			fk := images.FormatText
 			if opts.jsonOutput {
 				fk = images.FormatJSON
 			}
-			imo := images.NewOutput(updaterImage)
+			imo := images.NewOutput(commonOpts.Images, getUpdaterImageKey(commonOpts.UpdaterType))
 			var of images.Formatter = imo
 			if opts.rawOutput {
 				of = imo.ToList()
@@ -57,13 +54,13 @@ func NewImagesCommand(env *deployer.Environment, commonOpts *deploy.Options) *co
 	}
 	images.Flags().BoolVarP(&opts.jsonOutput, "json", "J", false, "output JSON, not text (default).")
 	images.Flags().BoolVarP(&opts.rawOutput, "raw", "r", false, "output raw list. Default is key=value object.")
-	images.Flags().BoolVarP(&opts.useSHA, "sha", "S", false, "emit SHA256 pullspects, not tag pullspecs.")
+	images.Flags().BoolVarP(&commonOpts.ImagesUseSHA, "sha", "S", false, "emit SHA256 pullspects, not tag pullspecs.")
 	return images
 }
 
-func getUpdaterImage(updaterType string) string {
+func getUpdaterImageKey(updaterType string) string {
 	if updaterType == updaters.RTE {
-		return images.ResourceTopologyExporterImage
+		return images.KeyResourceTopologyExporter
 	}
-	return images.NodeFeatureDiscoveryImage
+	return images.KeyNodeFeatureDiscovery
 }
@@ -0,0 +1,86 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/olm"
+)
+
+const (
+	OLMPackageName    = "topology-aware-scheduling"
+	OLMBundleVersion  = "0.1.0"
+	OLMDefaultChannel = "alpha"
+)
+
+// writeOLMBundle renders the manifests through the same code path of the other formats
+// and packs them in a registry+v1 bundle. The bundle holds the API and the scheduler only,
+// because OLM can't install the topology updater DaemonSet, nor the MachineConfig and SCC
+// it needs on OpenShift. Fails if any other object can't be installed by OLM.
+func writeOLMBundle(env *deployer.Environment, commonOpts *deploy.Options, opts *RenderOptions) error {
+	apiCRD, err := manifests.APICRD()
+	if err != nil {
+		return err
+	}
+	schedCRD, err := manifests.SchedulerCRD()
+	if err != nil {
+		return err
+	}
+
+	// OLM picks the install namespace: keep the lease in a namespace which always exists.
+	// The scheduler cluster role grants its lease in any namespace.
+	olmOpts := *commonOpts
+	if olmOpts.SchedLeaseNamespace == "" {
+		olmOpts.SchedLeaseNamespace = metav1.NamespaceSystem
+	}
+	comps, err := RenderComponents(env, &olmOpts)
+	if err != nil {
+		return err
+	}
+	var objs []client.Object
+	for _, comp := range comps {
+		if comp.Name == ComponentTopologyUpdater {
+			env.Log.Info("topology updater left out of the OLM bundle, must be deployed separately")
+			continue
+		}
+		for _, obj := range comp.Objects {
+			// the CRDs are added to the bundle as owned CRDs
+			if _, ok := obj.(*apiextensionv1.CustomResourceDefinition); ok {
+				continue
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	bd := olm.Bundle{
+		PackageName:    OLMPackageName,
+		DisplayName:    "Topology Aware Scheduling",
+		Description:    "topology-aware-scheduling components for " + commonOpts.UserPlatform.String(),
+		Version:        OLMBundleVersion,
+		Channels:       []string{OLMDefaultChannel},
+		DefaultChannel: OLMDefaultChannel,
+		CRDs:           []*apiextensionv1.CustomResourceDefinition{apiCRD, schedCRD},
+		Objects:        objs,
+	}
+	return olm.Write(opts.OutputDir, bd)
+}
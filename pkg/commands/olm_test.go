@@ -0,0 +1,117 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/olm"
+)
+
+func TestWriteOLMBundleNamespaces(t *testing.T) {
+	type testCase struct {
+		name string
+		args []string
+	}
+
+	testCases := []testCase{
+		{
+			name: "defaults",
+			args: []string{"--platform=openshift:v4.14"},
+		},
+		{
+			name: "leader election",
+			args: []string{"--platform=openshift:v4.14", "--sched-leader-elect"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := &deployer.Environment{Ctx: context.Background(), Log: logr.Discard()}
+			commonOpts := &deploy.Options{}
+			internalOpts := &internalOptions{}
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			InitFlags(flags, commonOpts, internalOpts)
+			if err := flags.Parse(tc.args); err != nil {
+				t.Fatalf("cannot parse the flags: %v", err)
+			}
+			if err := PostSetupOptions(env, commonOpts, internalOpts); err != nil {
+				t.Fatalf("cannot setup the options: %v", err)
+			}
+
+			// OLM picks the install namespace: the bundle must not depend on the rendered ones
+			comps, err := RenderComponents(env, commonOpts)
+			if err != nil {
+				t.Fatalf("cannot render the components: %v", err)
+			}
+			var renderedNamespaces []string
+			for _, comp := range comps {
+				for _, obj := range comp.Objects {
+					if ns, ok := obj.(*corev1.Namespace); ok {
+						renderedNamespaces = append(renderedNamespaces, ns.Name)
+					}
+				}
+			}
+
+			dir := t.TempDir()
+			if err := writeOLMBundle(env, commonOpts, &RenderOptions{Format: RenderFormatOLMBundle, OutputDir: dir}); err != nil {
+				t.Fatalf("cannot write the OLM bundle: %v", err)
+			}
+
+			manifestsDir := filepath.Join(dir, olm.ManifestsDirName)
+			entries, err := os.ReadDir(manifestsDir)
+			if err != nil {
+				t.Fatalf("cannot read the bundle manifests: %v", err)
+			}
+			for _, entry := range entries {
+				data, err := os.ReadFile(filepath.Join(manifestsDir, entry.Name()))
+				if err != nil {
+					t.Fatalf("cannot read %q: %v", entry.Name(), err)
+				}
+				for _, ns := range renderedNamespaces {
+					if strings.Contains(string(data), ns) {
+						t.Errorf("%q references the namespace %q", entry.Name(), ns)
+					}
+				}
+				var obj struct {
+					Metadata struct {
+						Namespace string `json:"namespace"`
+					} `json:"metadata"`
+				}
+				if err := yaml.Unmarshal(data, &obj); err != nil {
+					t.Fatalf("cannot decode %q: %v", entry.Name(), err)
+				}
+				if obj.Metadata.Namespace != "" {
+					t.Errorf("%q is bound to the namespace %q", entry.Name(), obj.Metadata.Namespace)
+				}
+			}
+		})
+	}
+}
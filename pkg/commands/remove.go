@@ -17,7 +17,9 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -30,7 +32,17 @@ import (
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/updaters"
 )
 
+type removeOptions struct {
+	jsonOutput bool
+}
+
+type removalOutput struct {
+	Success bool                   `json:"success"`
+	Errors  []deployer.ObjectError `json:"errors,omitempty"`
+}
+
 func NewRemoveCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+	opts := &removeOptions{}
 	remove := &cobra.Command{
 		Use:   "remove",
 		Short: "remove the components and configurations needed for topology-aware-scheduling",
@@ -53,51 +65,67 @@ func NewRemoveCommand(env *deployer.Environment, commonOpts *deploy.Options) *co
 			}
 			env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
 
-			err = sched.Remove(env, sched.Options{
-				Platform:          commonOpts.ClusterPlatform,
-				WaitCompletion:    commonOpts.WaitCompletion,
-				Replicas:          int32(commonOpts.Replicas),
-				RTEConfigData:     commonOpts.RTEConfigData,
-				PullIfNotPresent:  commonOpts.PullIfNotPresent,
-				ProfileName:       commonOpts.SchedProfileName,
-				CacheResyncPeriod: commonOpts.SchedResyncPeriod,
-				CtrlPlaneAffinity: commonOpts.SchedCtrlPlaneAffinity,
-			})
+			var errs deployer.ObjectErrors
+			err = sched.Remove(env, deploy.SchedOptionsFrom(commonOpts))
 			if err != nil {
 				// intentionally keep going to remove as much as possible
 				env.Log.Info("while removing", "error", err)
+				errs.Merge(err)
 			}
 			err = updaters.Remove(env, commonOpts.UpdaterType, updaters.Options{
-				Platform:        commonOpts.ClusterPlatform,
-				PlatformVersion: commonOpts.ClusterVersion,
-				WaitCompletion:  commonOpts.WaitCompletion,
-				RTEConfigData:   commonOpts.RTEConfigData,
-				DaemonSet:       deploy.DaemonSetOptionsFrom(commonOpts),
-				EnableCRIHooks:  commonOpts.UpdaterCRIHooksEnable,
+				Platform:                  commonOpts.ClusterPlatform,
+				PlatformVersion:           commonOpts.ClusterVersion,
+				WaitCompletion:            commonOpts.WaitCompletion,
+				RTEConfigData:             commonOpts.RTEConfigData,
+				DaemonSet:                 deploy.DaemonSetOptionsFrom(commonOpts),
+				EnableCRIHooks:            commonOpts.UpdaterCRIHooksEnable,
+				MachineConfigPoolSelector: commonOpts.MachineConfigPoolSelector,
+				Namespace:                 commonOpts.UpdaterNamespace,
+				Metadata:                  deploy.MetadataOptionsFrom(commonOpts),
+				ImagePull:                 deploy.ImagePullOptionsFrom(commonOpts),
 			})
 			if err != nil {
 				// intentionally keep going to remove as much as possible
 				env.Log.Info("while removing", "error", err)
+				errs.Merge(err)
 			}
-			err = api.Remove(env, api.Options{
-				Platform: commonOpts.ClusterPlatform,
-			})
+			err = api.Remove(env, deploy.APIOptionsFrom(commonOpts))
 			if err != nil {
 				// intentionally keep going to remove as much as possible
 				env.Log.Info("while removing", "error", err)
+				errs.Merge(err)
 			}
-			return nil
+			return reportRemoval(opts, errs.AsError())
 		},
 		Args: cobra.NoArgs,
 	}
 	remove.PersistentFlags().BoolVarP(&commonOpts.WaitCompletion, "wait", "W", false, "wait for removal to be all completed.")
-	remove.AddCommand(NewRemoveAPICommand(env, commonOpts))
-	remove.AddCommand(NewRemoveSchedulerPluginCommand(env, commonOpts))
-	remove.AddCommand(NewRemoveTopologyUpdaterCommand(env, commonOpts))
+	remove.PersistentFlags().BoolVarP(&opts.jsonOutput, "json", "J", false, "output a JSON summary of the removal.")
+	remove.AddCommand(NewRemoveAPICommand(env, commonOpts, opts))
+	remove.AddCommand(NewRemoveSchedulerPluginCommand(env, commonOpts, opts))
+	remove.AddCommand(NewRemoveTopologyUpdaterCommand(env, commonOpts, opts))
 	return remove
 }
 
-func NewRemoveAPICommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+// reportRemoval emits the removal summary, if requested, and returns the error unchanged
+// so the exit code reflects partial removals.
+func reportRemoval(opts *removeOptions, err error) error {
+	if !opts.jsonOutput {
+		return err
+	}
+	var errs deployer.ObjectErrors
+	errs.Merge(err)
+	encErr := json.NewEncoder(os.Stdout).Encode(removalOutput{
+		Success: len(errs) == 0,
+		Errors:  errs,
+	})
+	if encErr != nil {
+		return encErr
+	}
+	return err
+}
+
+func NewRemoveAPICommand(env *deployer.Environment, commonOpts *deploy.Options, opts *removeOptions) *cobra.Command {
 	remove := &cobra.Command{
 		Use:   "api",
 		Short: "remove the APIs needed for topology-aware-scheduling",
@@ -120,17 +148,15 @@ func NewRemoveAPICommand(env *deployer.Environment, commonOpts *deploy.Options)
 			}
 
 			env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
-			if err := api.Remove(env, api.Options{Platform: commonOpts.ClusterPlatform}); err != nil {
-				return err
-			}
-			return nil
+			err = api.Remove(env, deploy.APIOptionsFrom(commonOpts))
+			return reportRemoval(opts, err)
 		},
 		Args: cobra.NoArgs,
 	}
 	return remove
 }
 
-func NewRemoveSchedulerPluginCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+func NewRemoveSchedulerPluginCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *removeOptions) *cobra.Command {
 	remove := &cobra.Command{
 		Use:   "scheduler-plugin",
 		Short: "remove the scheduler plugin needed for topology-aware-scheduling",
@@ -153,24 +179,15 @@ func NewRemoveSchedulerPluginCommand(env *deployer.Environment, commonOpts *depl
 			}
 
 			env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
-			return sched.Remove(env, sched.Options{
-				Platform:          commonOpts.ClusterPlatform,
-				WaitCompletion:    commonOpts.WaitCompletion,
-				Replicas:          int32(commonOpts.Replicas),
-				RTEConfigData:     commonOpts.RTEConfigData,
-				PullIfNotPresent:  commonOpts.PullIfNotPresent,
-				ProfileName:       commonOpts.SchedProfileName,
-				CacheResyncPeriod: commonOpts.SchedResyncPeriod,
-				CtrlPlaneAffinity: commonOpts.SchedCtrlPlaneAffinity,
-				Verbose:           commonOpts.SchedVerbose,
-			})
+			err = sched.Remove(env, deploy.SchedOptionsFrom(commonOpts))
+			return reportRemoval(opts, err)
 		},
 		Args: cobra.NoArgs,
 	}
 	return remove
 }
 
-func NewRemoveTopologyUpdaterCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
+func NewRemoveTopologyUpdaterCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *removeOptions) *cobra.Command {
 	remove := &cobra.Command{
 		Use:   "topology-updater",
 		Short: "remove the topology updater needed for topology-aware-scheduling",
@@ -193,14 +210,19 @@ func NewRemoveTopologyUpdaterCommand(env *deployer.Environment, commonOpts *depl
 			}
 
 			env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
-			return updaters.Remove(env, commonOpts.UpdaterType, updaters.Options{
-				Platform:        commonOpts.ClusterPlatform,
-				PlatformVersion: commonOpts.ClusterVersion,
-				WaitCompletion:  commonOpts.WaitCompletion,
-				RTEConfigData:   commonOpts.RTEConfigData,
-				DaemonSet:       deploy.DaemonSetOptionsFrom(commonOpts),
-				EnableCRIHooks:  commonOpts.UpdaterCRIHooksEnable,
+			err = updaters.Remove(env, commonOpts.UpdaterType, updaters.Options{
+				Platform:                  commonOpts.ClusterPlatform,
+				PlatformVersion:           commonOpts.ClusterVersion,
+				WaitCompletion:            commonOpts.WaitCompletion,
+				RTEConfigData:             commonOpts.RTEConfigData,
+				DaemonSet:                 deploy.DaemonSetOptionsFrom(commonOpts),
+				EnableCRIHooks:            commonOpts.UpdaterCRIHooksEnable,
+				MachineConfigPoolSelector: commonOpts.MachineConfigPoolSelector,
+				Namespace:                 commonOpts.UpdaterNamespace,
+				Metadata:                  deploy.MetadataOptionsFrom(commonOpts),
+				ImagePull:                 deploy.ImagePullOptionsFrom(commonOpts),
 			})
+			return reportRemoval(opts, err)
 		},
 		Args: cobra.NoArgs,
 	}
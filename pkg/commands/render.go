@@ -21,21 +21,51 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/updaters"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/kustomize"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests/api"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests/sched"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 )
 
-type RenderOptions struct{}
+const (
+	RenderFormatYAML      = "yaml"
+	RenderFormatKustomize = "kustomize"
+	RenderFormatHelm      = "helm"
+	RenderFormatOLMBundle = "olm-bundle"
+)
+
+const (
+	RenderOutputYAML = "yaml"
+	RenderOutputJSON = "json"
+	RenderOutputList = "list"
+)
+
+// component names match the render subcommands
+const (
+	ComponentAPI              = "api"
+	ComponentSchedulerPlugin  = "scheduler-plugin"
+	ComponentTopologyUpdater  = "topology-updater"
+	ComponentSchedulerProfile = "scheduler-profile"
+)
+
+type RenderOptions struct {
+	Format    string
+	OutputDir string
+	Output    string
+}
 
 func NewRenderCommand(env *deployer.Environment, commonOpts *deploy.Options) *cobra.Command {
 	opts := &RenderOptions{}
+	checkOpts := &imageCheckOptions{}
 	render := &cobra.Command{
 		Use:   "render",
 		Short: "render all the manifests",
@@ -43,13 +73,38 @@ func NewRenderCommand(env *deployer.Environment, commonOpts *deploy.Options) *co
 			if commonOpts.UserPlatform == platform.Unknown {
 				return fmt.Errorf("must explicitly select a cluster platform")
 			}
-			return RenderManifests(env, commonOpts)
+			if err := validateRenderOptions(opts); err != nil {
+				return err
+			}
+			// the chart values set the images, so there are no pinned images to annotate with their origins
+			if opts.Format == RenderFormatHelm && (checkOpts.resolveDigests || checkOpts.verifyKeyFile != "") {
+				return fmt.Errorf("format %q cannot pin the images to digests: set the pinned images in the chart values instead of using --resolve-digests or --verify-signatures-key", opts.Format)
+			}
+			if err := checkImages(env, commonOpts, checkOpts); err != nil {
+				return err
+			}
+			if opts.Format == RenderFormatHelm {
+				return writeHelmChart(env, commonOpts, opts.OutputDir)
+			}
+			if opts.Format == RenderFormatOLMBundle {
+				return writeOLMBundle(env, commonOpts, opts)
+			}
+			comps, err := RenderComponents(env, commonOpts)
+			if err != nil {
+				return err
+			}
+			return writeComponents(opts, comps)
 		},
 		Args: cobra.NoArgs,
 	}
+	render.PersistentFlags().StringVar(&opts.Format, "format", RenderFormatYAML, "output format: yaml, kustomize, helm or olm-bundle. All but yaml need --output-dir. The OLM bundle holds the API and the scheduler: OLM can't install the topology updater, which must be deployed separately.")
+	render.PersistentFlags().StringVar(&opts.Output, "output", RenderOutputYAML, "encoding of the manifests written on stdout: yaml (multi-document stream), json (stream of objects) or list (single v1/List object).")
+	render.PersistentFlags().StringVar(&opts.OutputDir, "output-dir", "", "write the rendered manifests in this directory. With yaml format, writes one file per object and an index of the apply order.")
+	addImageCheckFlags(render.PersistentFlags(), checkOpts)
 	render.AddCommand(NewRenderAPICommand(env, commonOpts, opts))
-	render.AddCommand(NewRenderSchedulerPluginCommand(env, commonOpts, opts))
-	render.AddCommand(NewRenderTopologyUpdaterCommand(env, commonOpts, opts))
+	render.AddCommand(NewRenderSchedulerPluginCommand(env, commonOpts, opts, checkOpts))
+	render.AddCommand(NewRenderTopologyUpdaterCommand(env, commonOpts, opts, checkOpts))
+	render.AddCommand(NewRenderSchedulerProfileCommand(env, commonOpts, opts))
 	return render
 }
 
@@ -61,22 +116,23 @@ func NewRenderAPICommand(env *deployer.Environment, commonOpts *deploy.Options,
 			if commonOpts.UserPlatform == platform.Unknown {
 				return fmt.Errorf("must explicitly select a cluster platform")
 			}
-			apiManifests, err := api.GetManifests(commonOpts.UserPlatform)
-			if err != nil {
+			if err := validateComponentRenderOptions(opts); err != nil {
 				return err
 			}
-			apiObjs, err := apiManifests.Render()
+			apiObjs, err := makeAPIObjects(commonOpts)
 			if err != nil {
 				return err
 			}
-			return manifests.RenderObjects(apiObjs.ToObjects(), os.Stdout)
+			return writeComponents(opts, []manifests.Component{
+				{Name: ComponentAPI, Objects: apiObjs},
+			})
 		},
 		Args: cobra.NoArgs,
 	}
 	return render
 }
 
-func NewRenderSchedulerPluginCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *RenderOptions) *cobra.Command {
+func NewRenderSchedulerPluginCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *RenderOptions, checkOpts *imageCheckOptions) *cobra.Command {
 	render := &cobra.Command{
 		Use:   "scheduler-plugin",
 		Short: "render the scheduler plugin needed for topology-aware-scheduling",
@@ -84,33 +140,26 @@ func NewRenderSchedulerPluginCommand(env *deployer.Environment, commonOpts *depl
 			if commonOpts.UserPlatform == platform.Unknown {
 				return fmt.Errorf("must explicitly select a cluster platform")
 			}
-
-			_, namespace, err := updaters.SetupNamespace(commonOpts.UpdaterType)
-			if err != nil {
+			if err := validateComponentRenderOptions(opts); err != nil {
 				return err
 			}
-
-			schedManifests, err := sched.GetManifests(commonOpts.UserPlatform, namespace)
-			if err != nil {
+			if err := checkImages(env, commonOpts, checkOpts); err != nil {
 				return err
 			}
-
-			renderOpts := sched.RenderOptions{
-				Replicas:         int32(commonOpts.Replicas),
-				PullIfNotPresent: commonOpts.PullIfNotPresent,
-			}
-			schedObjs, err := schedManifests.Render(env.Log, renderOpts)
+			schedObjs, err := makeSchedObjects(env, commonOpts)
 			if err != nil {
 				return err
 			}
-			return manifests.RenderObjects(schedObjs.ToObjects(), os.Stdout)
+			return writeComponents(opts, []manifests.Component{
+				{Name: ComponentSchedulerPlugin, Objects: schedObjs},
+			})
 		},
 		Args: cobra.NoArgs,
 	}
 	return render
 }
 
-func NewRenderTopologyUpdaterCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *RenderOptions) *cobra.Command {
+func NewRenderTopologyUpdaterCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *RenderOptions, checkOpts *imageCheckOptions) *cobra.Command {
 	render := &cobra.Command{
 		Use:   "topology-updater",
 		Short: "render the topology updater needed for topology-aware-scheduling",
@@ -118,76 +167,177 @@ func NewRenderTopologyUpdaterCommand(env *deployer.Environment, commonOpts *depl
 			if commonOpts.UserPlatform == platform.Unknown {
 				return fmt.Errorf("must explicitly select a cluster platform")
 			}
-			objs, _, err := makeUpdaterObjects(commonOpts)
+			if err := validateComponentRenderOptions(opts); err != nil {
+				return err
+			}
+			if err := checkImages(env, commonOpts, checkOpts); err != nil {
+				return err
+			}
+			objs, err := makeUpdaterObjects(commonOpts)
 			if err != nil {
 				return err
 			}
-			return manifests.RenderObjects(objs, os.Stdout)
+			return writeComponents(opts, []manifests.Component{
+				{Name: ComponentTopologyUpdater, Objects: objs},
+			})
 		},
 		Args: cobra.NoArgs,
 	}
 	return render
 }
 
-func makeUpdaterObjects(commonOpts *deploy.Options) ([]client.Object, string, error) {
-	ns, namespace, err := updaters.SetupNamespace(commonOpts.UpdaterType)
+func validateRenderOptions(opts *RenderOptions) error {
+	switch opts.Output {
+	case RenderOutputYAML:
+		// always supported
+	case RenderOutputJSON, RenderOutputList:
+		if opts.Format != RenderFormatYAML || opts.OutputDir != "" {
+			return fmt.Errorf("output %q is supported only writing on stdout", opts.Output)
+		}
+	default:
+		return fmt.Errorf("unsupported render output: %q", opts.Output)
+	}
+
+	switch opts.Format {
+	case RenderFormatYAML:
+		// --output-dir is optional
+	case RenderFormatKustomize, RenderFormatHelm, RenderFormatOLMBundle:
+		if opts.OutputDir == "" {
+			return fmt.Errorf("format %q requires --output-dir", opts.Format)
+		}
+	default:
+		return fmt.Errorf("unsupported render format: %q", opts.Format)
+	}
+	return nil
+}
+
+// the helm chart and the OLM bundle need to render all the components at once
+func validateComponentRenderOptions(opts *RenderOptions) error {
+	if opts.Format == RenderFormatHelm || opts.Format == RenderFormatOLMBundle {
+		return fmt.Errorf("format %q is supported only rendering all the components", opts.Format)
+	}
+	return validateRenderOptions(opts)
+}
+
+func writeComponents(opts *RenderOptions, comps []manifests.Component) error {
+	if opts.Format == RenderFormatKustomize {
+		return kustomize.WriteBase(opts.OutputDir, comps)
+	}
+	if opts.OutputDir != "" {
+		return manifests.WriteTree(opts.OutputDir, comps)
+	}
+	var objs []client.Object
+	for _, comp := range comps {
+		objs = append(objs, comp.Objects...)
+	}
+	switch opts.Output {
+	case RenderOutputJSON:
+		return manifests.RenderObjectsJSON(objs, os.Stdout)
+	case RenderOutputList:
+		return manifests.RenderObjectsList(objs, os.Stdout)
+	}
+	return manifests.RenderObjects(objs, os.Stdout)
+}
+
+func makeAPIObjects(commonOpts *deploy.Options) ([]client.Object, error) {
+	apiManifests, err := api.GetManifests(commonOpts.UserPlatform)
+	if err != nil {
+		return nil, err
+	}
+	apiOpts := deploy.APIOptionsFrom(commonOpts)
+	apiObjs, err := apiManifests.Render(api.RenderOptions{
+		Metadata:           apiOpts.Metadata,
+		PriorityClassName:  apiOpts.PriorityClassName,
+		PriorityClassValue: apiOpts.PriorityClassValue,
+	})
 	if err != nil {
-		return nil, namespace, err
+		return nil, err
+	}
+	return apiObjs.ToObjects(), nil
+}
+
+func makeUpdaterObjects(commonOpts *deploy.Options) ([]client.Object, error) {
+	ns, namespace, err := updaters.SetupNamespace(commonOpts.UpdaterType, commonOpts.UpdaterNamespace)
+	if err != nil {
+		return nil, err
 	}
 
 	opts := updaters.Options{
-		PlatformVersion: commonOpts.UserPlatformVersion,
-		Platform:        commonOpts.UserPlatform,
-		RTEConfigData:   commonOpts.RTEConfigData,
-		DaemonSet:       deploy.DaemonSetOptionsFrom(commonOpts),
-		EnableCRIHooks:  commonOpts.UpdaterCRIHooksEnable,
+		PlatformVersion:           commonOpts.UserPlatformVersion,
+		Platform:                  commonOpts.UserPlatform,
+		RTEConfigData:             commonOpts.RTEConfigData,
+		DaemonSet:                 deploy.DaemonSetOptionsFrom(commonOpts),
+		EnableCRIHooks:            commonOpts.UpdaterCRIHooksEnable,
+		MachineConfigPoolSelector: commonOpts.MachineConfigPoolSelector,
+		Namespace:                 commonOpts.UpdaterNamespace,
+		Metadata:                  deploy.MetadataOptionsFrom(commonOpts),
+		ImagePull:                 deploy.ImagePullOptionsFrom(commonOpts),
 	}
 	objs, err := updaters.GetObjects(opts, commonOpts.UpdaterType, namespace)
 	if err != nil {
-		return nil, namespace, err
+		return nil, err
 	}
 
-	return append([]client.Object{ns}, objs...), namespace, nil
+	// some updaters already own their namespace
+	for _, obj := range objs {
+		if _, ok := obj.(*corev1.Namespace); ok && obj.GetName() == ns.Name {
+			return objs, nil
+		}
+	}
+	objectupdate.Metadata([]client.Object{ns}, opts.Metadata)
+	return append([]client.Object{ns}, objs...), nil
 }
 
-func RenderManifests(env *deployer.Environment, commonOpts *deploy.Options) error {
-	var objs []client.Object
-
-	apiManifests, err := api.GetManifests(commonOpts.UserPlatform)
+func makeSchedObjects(env *deployer.Environment, commonOpts *deploy.Options) ([]client.Object, error) {
+	schedManifests, err := sched.GetManifests(commonOpts.UserPlatform, commonOpts.SchedNamespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	apiObjs, err := apiManifests.Render()
+
+	schedObjs, err := schedManifests.Render(env.Log, schedRenderOptionsFrom(commonOpts))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	objs = append(objs, apiObjs.ToObjects()...)
+	return schedObjs.ToObjects(), nil
+}
+
+// schedRenderOptionsFrom renders for the platform and version given by the user, not detected
+func schedRenderOptionsFrom(commonOpts *deploy.Options) sched.RenderOptions {
+	opts := deploy.SchedOptionsFrom(commonOpts)
+	opts.Platform = commonOpts.UserPlatform
+	opts.PlatformVersion = commonOpts.UserPlatformVersion
+	return opts.RenderOptions()
+}
 
-	updaterObjs, updaterNs, err := makeUpdaterObjects(commonOpts)
+// RenderComponents renders all the manifests, grouped by component, in the same order they are deployed.
+func RenderComponents(env *deployer.Environment, commonOpts *deploy.Options) ([]manifests.Component, error) {
+	apiObjs, err := makeAPIObjects(commonOpts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	objs = append(objs, updaterObjs...)
 
-	schedManifests, err := sched.GetManifests(commonOpts.UserPlatform, updaterNs)
+	updaterObjs, err := makeUpdaterObjects(commonOpts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	schedRenderOpts := sched.RenderOptions{
-		Replicas:          int32(commonOpts.Replicas),
-		PullIfNotPresent:  commonOpts.PullIfNotPresent,
-		ProfileName:       commonOpts.SchedProfileName,
-		CacheResyncPeriod: commonOpts.SchedResyncPeriod,
-		CtrlPlaneAffinity: commonOpts.SchedCtrlPlaneAffinity,
-		Verbose:           commonOpts.SchedVerbose,
+	schedObjs, err := makeSchedObjects(env, commonOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	schedObjs, err := schedManifests.Render(env.Log, schedRenderOpts)
+	return []manifests.Component{
+		{Name: ComponentAPI, Objects: apiObjs},
+		{Name: ComponentTopologyUpdater, Objects: updaterObjs},
+		{Name: ComponentSchedulerPlugin, Objects: schedObjs},
+	}, nil
+}
+
+// RenderManifests renders all the manifests as a single YAML stream on stdout.
+func RenderManifests(env *deployer.Environment, commonOpts *deploy.Options) error {
+	comps, err := RenderComponents(env, commonOpts)
 	if err != nil {
 		return err
 	}
-	objs = append(objs, schedObjs.ToObjects()...)
-
-	return manifests.RenderObjects(objs, os.Stdout)
+	return writeComponents(&RenderOptions{Format: RenderFormatYAML, Output: RenderOutputYAML}, comps)
 }
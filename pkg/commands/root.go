@@ -18,9 +18,11 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,11 +30,18 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/updaters"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/wait"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 )
 
 // TODO: move elsewhere
@@ -40,11 +49,28 @@ const (
 	DefaultSchedulerProfileName  = "topology-aware-scheduler"
 	DefaultSchedulerResyncPeriod = 5 * time.Second
 	DefaultUpdaterSyncPeriod     = 10 * time.Second
+	DefaultPriorityClassValue    = 1000000
+	// values above are reserved to the system priority classes
+	highestUserDefinablePriority = 1000000000
 )
 
 type internalOptions struct {
 	rteConfigFile string
 	plat          string
+	mcpSelector   string
+	nodeSelector  string
+	tolerations   []string
+	// resources
+	resourcesPreset    string
+	containerResources []string
+	// docker config file to create the pull secret from
+	pullSecretFile string
+	imageSetFile   string
+	images         map[string]string
+	// scheduler scoring strategy
+	schedScoringStrategy  string
+	schedScoringResources []string
+	schedProfilesFile     string
 }
 
 func ShowHelp(cmd *cobra.Command, args []string) error {
@@ -87,6 +113,7 @@ func NewRootCommand(extraCmds ...NewCommandFunc) *cobra.Command {
 		NewSetupCommand(&env, &commonOpts),
 		NewDetectCommand(&env, &commonOpts),
 		NewImagesCommand(&env, &commonOpts),
+		NewBundleCommand(&env, &commonOpts),
 	)
 	for _, extraCmd := range extraCmds {
 		root.AddCommand(extraCmd(&env, &commonOpts))
@@ -98,6 +125,7 @@ func NewRootCommand(extraCmds ...NewCommandFunc) *cobra.Command {
 func InitFlags(flags *pflag.FlagSet, commonOpts *deploy.Options, internalOpts *internalOptions) {
 	flags.StringVarP(&internalOpts.plat, "platform", "P", "", "platform kind:version to deploy on (example kubernetes:v1.22)")
 	flags.StringVar(&internalOpts.rteConfigFile, "rte-config-file", "", "inject rte configuration reading from this file.")
+	flags.StringVar(&internalOpts.mcpSelector, "mcp-selector", "", "labels (key=value,...) of the rte MachineConfig selecting the target MachineConfigPools. OpenShift only.")
 
 	flags.IntVarP(&commonOpts.Replicas, "replicas", "R", 1, "set the replica value - where relevant.")
 	flags.DurationVarP(&commonOpts.WaitInterval, "wait-interval", "E", 2*time.Second, "wait interval.")
@@ -109,10 +137,38 @@ func InitFlags(flags *pflag.FlagSet, commonOpts *deploy.Options, internalOpts *i
 	flags.BoolVar(&commonOpts.UpdaterCRIHooksEnable, "updater-cri-hooks-enable", true, "toggle installation of CRI hooks on the updater side.")
 	flags.DurationVar(&commonOpts.UpdaterSyncPeriod, "updater-sync-period", DefaultUpdaterSyncPeriod, "tune the updater synchronization (nrt update) interval. Use 0 to disable.")
 	flags.IntVar(&commonOpts.UpdaterVerbose, "updater-verbose", 1, "set the updater verbosiness.")
+	flags.StringVar(&internalOpts.nodeSelector, "updater-node-selector", "", "label selector of the nodes to run the updater on (example: 'node-role.kubernetes.io/worker,zone in (a,b)').")
+	flags.StringVar(&commonOpts.UpdaterNamespace, "updater-namespace", "", "namespace to deploy the updater into. Leave empty for the default.")
+	flags.StringSliceVar(&internalOpts.tolerations, "updater-tolerations", nil, "tolerations of the updater pods, as key[=value][:effect] (example: 'foo=bar:NoSchedule').")
 	flags.StringVar(&commonOpts.SchedProfileName, "sched-profile-name", DefaultSchedulerProfileName, "inject scheduler profile name.")
 	flags.DurationVar(&commonOpts.SchedResyncPeriod, "sched-resync-period", DefaultSchedulerResyncPeriod, "inject scheduler resync period.")
+	flags.StringVar(&commonOpts.SchedCacheResyncMethod, "sched-cache-resync-method", "", "resync method of the scheduler cache: "+strings.Join(manifests.CacheResyncMethods(), ", ")+". Leave empty for the plugin default.")
+	flags.StringVar(&commonOpts.SchedCacheForeignPodsDetect, "sched-cache-foreign-pods-detect", "", "foreign pods detection mode of the scheduler cache: "+strings.Join(manifests.ForeignPodsDetectModes(), ", ")+". Leave empty for the plugin default.")
+	flags.StringVar(&commonOpts.SchedCacheInformerMode, "sched-cache-informer-mode", "", "informer mode of the scheduler cache: "+strings.Join(manifests.CacheInformerModes(), ", ")+". Leave empty for the plugin default.")
+	flags.StringVar(&internalOpts.schedScoringStrategy, "sched-scoring-strategy", "", "scoring strategy of the scheduler plugin: "+strings.Join(manifests.ScoringStrategyTypes(), ", ")+". Leave empty for the plugin default.")
+	flags.StringSliceVar(&internalOpts.schedScoringResources, "sched-scoring-resources", nil, "resources the scoring strategy weighs, as name=weight (example: 'cpu=1,memory=2'). Requires --sched-scoring-strategy.")
+	flags.StringVar(&internalOpts.schedProfilesFile, "sched-profiles-file", "", "YAML file with the scheduler profiles to render, each with its schedulerName and plugin args, replacing --sched-profile-name. The unset args are taken from the flags.")
+	flags.BoolVar(&commonOpts.SchedLeaderElection, "sched-leader-elect", false, "enable the leader election of the scheduler and the controller, and spread their replicas across the nodes. Always enabled with more than one replica.")
+	flags.StringVar(&commonOpts.SchedLeaseName, "sched-lease-name", "", "name of the scheduler leader election lease. Leave empty for the default.")
+	flags.StringVar(&commonOpts.SchedLeaseNamespace, "sched-lease-namespace", "", "namespace of the scheduler leader election lease. Leave empty for the scheduler namespace.")
 	flags.IntVar(&commonOpts.SchedVerbose, "sched-verbose", 4, "set the scheduler verbosiness.")
 	flags.BoolVar(&commonOpts.SchedCtrlPlaneAffinity, "sched-ctrlplane-affinity", true, "toggle the scheduler control plane affinity.")
+	flags.StringVar(&internalOpts.resourcesPreset, "resources-preset", "", "resources of all the containers: small, medium or large. Leave empty to keep the manifests defaults.")
+	flags.StringArrayVar(&internalOpts.containerResources, "container-resources", nil, "resources of a container, overriding the preset, as name:requests.cpu=100m,limits.memory=200Mi. Can be repeated.")
+	flags.BoolVar(&commonOpts.UpdaterGuaranteedQoS, "updater-guaranteed-qos", false, "make the updater pods Guaranteed QoS, setting the limits equal to the requests. Requires --resources-preset, which then gives the updater whole cpus, or --container-resources.")
+	flags.StringToStringVar(&commonOpts.CommonLabels, "labels", nil, "labels to add to all the objects and the pods (example: 'team=foo,cost-center=bar').")
+	flags.StringToStringVar(&commonOpts.CommonAnnotations, "annotations", nil, "annotations to add to all the objects.")
+	flags.StringVar(&commonOpts.NamePrefix, "name-prefix", "", "prefix to add to the names of all the objects but CRDs and namespaces.")
+	flags.StringVar(&commonOpts.NameSuffix, "name-suffix", "", "suffix to add to the names of all the objects but CRDs and namespaces.")
+	flags.StringVar(&commonOpts.PriorityClassName, "priority-class", "", "priority class of the scheduler, controller and updater pods. Leave empty for none.")
+	flags.BoolVar(&commonOpts.CreatePriorityClass, "create-priority-class", false, "render the priority class given with --priority-class along the API.")
+	flags.Int32Var(&commonOpts.PriorityClassValue, "priority-class-value", DefaultPriorityClassValue, "value of the priority class rendered by --create-priority-class.")
+	flags.StringSliceVar(&commonOpts.ImagePullSecrets, "image-pull-secrets", nil, "image pull secrets to add to all the service accounts and pods.")
+	flags.StringVar(&internalOpts.pullSecretFile, "image-pull-secret-file", "", "docker config file to create the first of --image-pull-secrets from, in each namespace.")
+	flags.StringVar(&internalOpts.imageSetFile, "image-set-file", "", "YAML or JSON file with the images to use, overriding the environment. Keys: "+strings.Join(images.Keys(), ", ")+".")
+	flags.StringToStringVar(&internalOpts.images, "image", nil, "images to use, overriding the image set file, as key=pullspec (example: 'pause=registry.k8s.io/pause:3.9').")
+	flags.StringVar(&commonOpts.ImageRegistryMirror, "image-registry-mirror", "", "registry, optionally with a path, to pull all the images from (example: 'mirror.lan:5000/tas').")
+	flags.StringVar(&commonOpts.SchedNamespace, "sched-namespace", "", "namespace to deploy the scheduler into. Leave empty for the platform default.")
 }
 
 func PostSetupOptions(env *deployer.Environment, commonOpts *deploy.Options, internalOpts *internalOptions) error {
@@ -142,12 +198,308 @@ func PostSetupOptions(env *deployer.Environment, commonOpts *deploy.Options, int
 		commonOpts.RTEConfigData = string(data)
 		env.Log.Info("RTE config: read", "bytes", len(commonOpts.RTEConfigData))
 	}
+
+	if internalOpts.nodeSelector != "" {
+		sel, err := metav1.ParseToLabelSelector(internalOpts.nodeSelector)
+		if err != nil {
+			return fmt.Errorf("invalid updater node selector %q: %w", internalOpts.nodeSelector, err)
+		}
+		commonOpts.UpdaterNodeSelector = sel
+	}
+
+	for _, val := range internalOpts.tolerations {
+		toleration, err := parseToleration(val)
+		if err != nil {
+			return err
+		}
+		commonOpts.UpdaterTolerations = append(commonOpts.UpdaterTolerations, toleration)
+	}
+
+	if internalOpts.mcpSelector != "" {
+		sel, err := parseMachineConfigPoolSelector(internalOpts.mcpSelector)
+		if err != nil {
+			return err
+		}
+		commonOpts.MachineConfigPoolSelector = sel
+	}
+
+	if internalOpts.pullSecretFile != "" {
+		if len(commonOpts.ImagePullSecrets) == 0 {
+			return fmt.Errorf("cannot create a pull secret without a name: use --image-pull-secrets")
+		}
+		data, err := os.ReadFile(internalOpts.pullSecretFile)
+		if err != nil {
+			return err
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("invalid docker config file %q: not JSON", internalOpts.pullSecretFile)
+		}
+		commonOpts.ImagePullSecretData = data
+		env.Log.Info("pull secret: read", "bytes", len(commonOpts.ImagePullSecretData))
+	}
+	if err := setupImages(commonOpts, internalOpts); err != nil {
+		return err
+	}
+
+	if err := setupContainerResources(commonOpts, internalOpts); err != nil {
+		return err
+	}
+
+	if err := setupSchedScoringStrategy(commonOpts, internalOpts); err != nil {
+		return err
+	}
+	if err := validateSchedCacheOptions(commonOpts); err != nil {
+		return err
+	}
+	if internalOpts.schedProfilesFile != "" {
+		data, err := os.ReadFile(internalOpts.schedProfilesFile)
+		if err != nil {
+			return err
+		}
+		profiles, err := manifests.DecodeSchedulerProfiles(data)
+		if err != nil {
+			return fmt.Errorf("invalid scheduler profiles file %q: %w", internalOpts.schedProfilesFile, err)
+		}
+		commonOpts.SchedProfiles = profiles
+		env.Log.Info("scheduler profiles: read", "count", len(profiles))
+	}
+
+	if err := validateMetadataOptions(commonOpts); err != nil {
+		return err
+	}
+	if err := validatePriorityClassOptions(commonOpts); err != nil {
+		return err
+	}
 	return validateUpdaterType(commonOpts.UpdaterType)
 }
 
+// setupImages merges, by increasing priority, the defaults, the environment, the image set file and the flags.
+func setupImages(commonOpts *deploy.Options, internalOpts *internalOptions) error {
+	_, useSHA := os.LookupEnv(images.EnvUseSHA)
+	imgs := images.Defaults(useSHA || commonOpts.ImagesUseSHA).Merge(images.FromEnv(os.LookupEnv))
+	if internalOpts.imageSetFile != "" {
+		fileImgs, err := images.FromFile(internalOpts.imageSetFile)
+		if err != nil {
+			return err
+		}
+		imgs = imgs.Merge(fileImgs)
+	}
+	for key, image := range internalOpts.images {
+		if err := imgs.Set(key, image, images.SourceFlag); err != nil {
+			return err
+		}
+	}
+	commonOpts.Images = imgs.WithRegistryMirror(commonOpts.ImageRegistryMirror)
+	return nil
+}
+
+// setupContainerResources starts from the preset, if any, and merges the explicit container resources.
+func setupContainerResources(commonOpts *deploy.Options, internalOpts *internalOptions) error {
+	if commonOpts.UpdaterGuaranteedQoS && internalOpts.resourcesPreset == "" && len(internalOpts.containerResources) == 0 {
+		return fmt.Errorf("--updater-guaranteed-qos requires the updater resources: set --resources-preset or --container-resources")
+	}
+	ret := make(map[string]corev1.ResourceRequirements)
+	if internalOpts.resourcesPreset != "" {
+		preset, err := manifests.ResourcesPreset(internalOpts.resourcesPreset, commonOpts.UpdaterGuaranteedQoS)
+		if err != nil {
+			return err
+		}
+		ret = preset
+	}
+	for _, val := range internalOpts.containerResources {
+		name, res, err := parseContainerResources(val)
+		if err != nil {
+			return err
+		}
+		cur := ret[name]
+		for resName, qty := range res.Requests {
+			if cur.Requests == nil {
+				cur.Requests = make(corev1.ResourceList)
+			}
+			cur.Requests[resName] = qty
+		}
+		for resName, qty := range res.Limits {
+			if cur.Limits == nil {
+				cur.Limits = make(corev1.ResourceList)
+			}
+			cur.Limits[resName] = qty
+		}
+		ret[name] = cur
+	}
+	if len(ret) > 0 {
+		commonOpts.ContainerResources = ret
+	}
+	return nil
+}
+
+// parseContainerResources parses name:requests.cpu=100m,limits.memory=200Mi
+func parseContainerResources(val string) (string, corev1.ResourceRequirements, error) {
+	var res corev1.ResourceRequirements
+	name, spec, ok := strings.Cut(val, ":")
+	if !ok || name == "" || spec == "" {
+		return "", res, fmt.Errorf("invalid container resources %q: expected name:resources", val)
+	}
+	for _, item := range strings.Split(spec, ",") {
+		key, qtyVal, ok := strings.Cut(item, "=")
+		if !ok {
+			return "", res, fmt.Errorf("invalid container resources %q: malformed item %q", val, item)
+		}
+		qty, err := resource.ParseQuantity(qtyVal)
+		if err != nil {
+			return "", res, fmt.Errorf("invalid container resources %q: %w", val, err)
+		}
+		kind, resName, ok := strings.Cut(key, ".")
+		if !ok || resName == "" {
+			return "", res, fmt.Errorf("invalid container resources %q: malformed key %q", val, key)
+		}
+		switch kind {
+		case "requests":
+			if res.Requests == nil {
+				res.Requests = make(corev1.ResourceList)
+			}
+			res.Requests[corev1.ResourceName(resName)] = qty
+		case "limits":
+			if res.Limits == nil {
+				res.Limits = make(corev1.ResourceList)
+			}
+			res.Limits[corev1.ResourceName(resName)] = qty
+		default:
+			return "", res, fmt.Errorf("invalid container resources %q: unknown key %q", val, key)
+		}
+	}
+	return name, res, nil
+}
+
+// setupSchedScoringStrategy builds the scoring strategy from the flags, if given.
+func setupSchedScoringStrategy(commonOpts *deploy.Options, internalOpts *internalOptions) error {
+	if internalOpts.schedScoringStrategy == "" {
+		if len(internalOpts.schedScoringResources) > 0 {
+			return fmt.Errorf("--sched-scoring-resources requires --sched-scoring-strategy")
+		}
+		return nil
+	}
+	sp := manifests.ScoringStrategyParams{
+		Type: internalOpts.schedScoringStrategy,
+	}
+	for _, val := range internalOpts.schedScoringResources {
+		name, weightVal, ok := strings.Cut(val, "=")
+		if !ok {
+			return fmt.Errorf("invalid scoring strategy resource %q: expected name=weight", val)
+		}
+		weight, err := strconv.ParseInt(weightVal, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid scoring strategy resource %q: %w", val, err)
+		}
+		sp.Resources = append(sp.Resources, manifests.ScoringStrategyResourceParams{
+			Name:   name,
+			Weight: weight,
+		})
+	}
+	if err := manifests.ValidateScoringStrategy(sp); err != nil {
+		return err
+	}
+	commonOpts.SchedScoringStrategy = &sp
+	return nil
+}
+
+func validateSchedCacheOptions(commonOpts *deploy.Options) error {
+	return manifests.ValidateCacheParams(manifests.ConfigCacheParams{
+		ResyncMethod:          stringIfSet(commonOpts.SchedCacheResyncMethod),
+		ForeignPodsDetectMode: stringIfSet(commonOpts.SchedCacheForeignPodsDetect),
+		InformerMode:          stringIfSet(commonOpts.SchedCacheInformerMode),
+	})
+}
+
+func stringIfSet(val string) *string {
+	if val == "" {
+		return nil
+	}
+	return &val
+}
+
+func validateMetadataOptions(commonOpts *deploy.Options) error {
+	for key, val := range commonOpts.CommonLabels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(val); len(errs) > 0 {
+			return fmt.Errorf("invalid label value %q: %s", val, strings.Join(errs, "; "))
+		}
+	}
+	for key := range commonOpts.CommonAnnotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation key %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	if commonOpts.NamePrefix == "" && commonOpts.NameSuffix == "" {
+		return nil
+	}
+	// any valid name must stay valid once decorated
+	if errs := validation.IsDNS1123Subdomain(commonOpts.NamePrefix + "x" + commonOpts.NameSuffix); len(errs) > 0 {
+		return fmt.Errorf("invalid name prefix %q or suffix %q: %s", commonOpts.NamePrefix, commonOpts.NameSuffix, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validatePriorityClassOptions(commonOpts *deploy.Options) error {
+	if commonOpts.PriorityClassName == "" {
+		if commonOpts.CreatePriorityClass {
+			return fmt.Errorf("cannot create a priority class without a name")
+		}
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(commonOpts.PriorityClassName); len(errs) > 0 {
+		return fmt.Errorf("invalid priority class %q: %s", commonOpts.PriorityClassName, strings.Join(errs, "; "))
+	}
+	if commonOpts.CreatePriorityClass && commonOpts.PriorityClassValue > highestUserDefinablePriority {
+		return fmt.Errorf("invalid priority class value %d: must not exceed %d", commonOpts.PriorityClassValue, highestUserDefinablePriority)
+	}
+	return nil
+}
+
+// the selector becomes the labels of the MachineConfig, so only plain key=value pairs make sense.
+func parseMachineConfigPoolSelector(val string) (*metav1.LabelSelector, error) {
+	sel, err := metav1.ParseToLabelSelector(val)
+	if err != nil {
+		return nil, fmt.Errorf("invalid machine config pool selector %q: %w", val, err)
+	}
+	if len(sel.MatchExpressions) > 0 || len(sel.MatchLabels) == 0 {
+		return nil, fmt.Errorf("invalid machine config pool selector %q: only key=value labels are supported", val)
+	}
+	return sel, nil
+}
+
 func validateUpdaterType(updaterType string) error {
 	if updaterType != updaters.RTE && updaterType != updaters.NFD {
 		return fmt.Errorf("%q is invalid updater type", updaterType)
 	}
 	return nil
 }
+
+// parseToleration parses key[=value][:effect], using the same format as `kubectl taint`.
+// Omitting the value tolerates any value, omitting the effect tolerates any effect.
+func parseToleration(val string) (corev1.Toleration, error) {
+	toleration := corev1.Toleration{
+		Operator: corev1.TolerationOpExists,
+	}
+	spec := val
+	if idx := strings.LastIndex(spec, ":"); idx != -1 {
+		toleration.Effect = corev1.TaintEffect(spec[idx+1:])
+		spec = spec[:idx]
+		switch toleration.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return toleration, fmt.Errorf("invalid toleration %q: unsupported effect %q", val, toleration.Effect)
+		}
+	}
+	if key, value, ok := strings.Cut(spec, "="); ok {
+		toleration.Operator = corev1.TolerationOpEqual
+		toleration.Value = value
+		spec = key
+	}
+	if spec == "" {
+		return toleration, fmt.Errorf("invalid toleration %q: missing key", val)
+	}
+	toleration.Key = spec
+	return toleration, nil
+}
@@ -0,0 +1,289 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+func TestParseToleration(t *testing.T) {
+	type testCase struct {
+		value       string
+		expected    corev1.Toleration
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			value: "foo",
+			expected: corev1.Toleration{
+				Key:      "foo",
+				Operator: corev1.TolerationOpExists,
+			},
+		},
+		{
+			value: "foo:NoSchedule",
+			expected: corev1.Toleration{
+				Key:      "foo",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			},
+		},
+		{
+			value: "foo=bar",
+			expected: corev1.Toleration{
+				Key:      "foo",
+				Operator: corev1.TolerationOpEqual,
+				Value:    "bar",
+			},
+		},
+		{
+			value: "node-role.kubernetes.io/infra=reserved:NoExecute",
+			expected: corev1.Toleration{
+				Key:      "node-role.kubernetes.io/infra",
+				Operator: corev1.TolerationOpEqual,
+				Value:    "reserved",
+				Effect:   corev1.TaintEffectNoExecute,
+			},
+		},
+		{
+			value:       "foo=bar:Sometimes",
+			expectError: true,
+		},
+		{
+			value:       "=bar:NoSchedule",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseToleration(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("toleration mismatch: got %#v expected %#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseContainerResources(t *testing.T) {
+	type testCase struct {
+		value        string
+		expectedName string
+		expected     corev1.ResourceRequirements
+		expectError  bool
+	}
+
+	testCases := []testCase{
+		{
+			value:        "foo:requests.cpu=100m,limits.memory=200Mi",
+			expectedName: "foo",
+			expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("200Mi")},
+			},
+		},
+		{
+			value:       "requests.cpu=100m",
+			expectError: true,
+		},
+		{
+			value:       "foo:cpu=100m",
+			expectError: true,
+		},
+		{
+			value:       "foo:requests.cpu=lots",
+			expectError: true,
+		},
+		{
+			value:       "foo:reserved.cpu=1",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			name, got, err := parseContainerResources(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %v %v", name, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if name != tc.expectedName {
+				t.Errorf("name mismatch: got %q expected %q", name, tc.expectedName)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("resources mismatch: got %#v expected %#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSetupSchedScoringStrategy(t *testing.T) {
+	type testCase struct {
+		name        string
+		strategy    string
+		resources   []string
+		expected    *manifests.ScoringStrategyParams
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "unset",
+		},
+		{
+			name:     "type only",
+			strategy: "LeastNUMANodes",
+			expected: &manifests.ScoringStrategyParams{
+				Type: manifests.ScoringStrategyLeastNUMANodes,
+			},
+		},
+		{
+			name:      "weighted resources",
+			strategy:  "MostAllocated",
+			resources: []string{"cpu=2", "memory=1"},
+			expected: &manifests.ScoringStrategyParams{
+				Type: manifests.ScoringStrategyMostAllocated,
+				Resources: []manifests.ScoringStrategyResourceParams{
+					{Name: "cpu", Weight: 2},
+					{Name: "memory", Weight: 1},
+				},
+			},
+		},
+		{
+			name:        "resources without type",
+			resources:   []string{"cpu=2"},
+			expectError: true,
+		},
+		{
+			name:        "malformed weight",
+			strategy:    "MostAllocated",
+			resources:   []string{"cpu=lots"},
+			expectError: true,
+		},
+		{
+			name:        "unknown type",
+			strategy:    "Random",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			commonOpts := deploy.Options{}
+			internalOpts := internalOptions{
+				schedScoringStrategy:  tc.strategy,
+				schedScoringResources: tc.resources,
+			}
+			err := setupSchedScoringStrategy(&commonOpts, &internalOpts)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %+v", commonOpts.SchedScoringStrategy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if !reflect.DeepEqual(commonOpts.SchedScoringStrategy, tc.expected) {
+				t.Errorf("scoring strategy mismatch: got %+v expected %+v", commonOpts.SchedScoringStrategy, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSetupContainerResourcesGuaranteed(t *testing.T) {
+	type testCase struct {
+		name        string
+		preset      string
+		containers  []string
+		expectedCPU string
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:        "no resources",
+			expectError: true,
+		},
+		{
+			name:        "preset",
+			preset:      manifests.ResourcesPresetMedium,
+			expectedCPU: "1",
+		},
+		{
+			name:        "explicit resources",
+			containers:  []string{manifests.ContainerNameRTE + ":requests.cpu=2"},
+			expectedCPU: "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			commonOpts := deploy.Options{
+				UpdaterGuaranteedQoS: true,
+			}
+			internalOpts := internalOptions{
+				resourcesPreset:    tc.preset,
+				containerResources: tc.containers,
+			}
+			err := setupContainerResources(&commonOpts, &internalOpts)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %+v", commonOpts.ContainerResources)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			res := commonOpts.ContainerResources[manifests.ContainerNameRTE]
+			cpu := res.Requests[corev1.ResourceCPU]
+			if cpu.Cmp(resource.MustParse(tc.expectedCPU)) != 0 {
+				t.Errorf("cpu request %s expected %s", cpu.String(), tc.expectedCPU)
+			}
+			if tc.preset == "" {
+				return
+			}
+			// the preset must make exclusive cpus reachable
+			if lim := res.Limits[corev1.ResourceCPU]; lim.Cmp(cpu) != 0 {
+				t.Errorf("cpu limit %s differs from request %s", lim.String(), cpu.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests/sched"
+	schedupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate/sched"
+)
+
+type schedProfileOptions struct {
+	existingConfig    string
+	mergedConfig      string
+	existingConfigMap string
+	configMapKey      string
+}
+
+func NewRenderSchedulerProfileCommand(env *deployer.Environment, commonOpts *deploy.Options, opts *RenderOptions) *cobra.Command {
+	profOpts := &schedProfileOptions{}
+	render := &cobra.Command{
+		Use:   "scheduler-profile",
+		Short: "render the topology-aware profile to merge into an existing kube-scheduler configuration",
+		Long: `Render the topology-aware profile to run as an extra profile of an existing kube-scheduler,
+instead of deploying a secondary scheduler, and the RBAC the existing scheduler needs.
+The existing kube-scheduler image must be replaced with a scheduler-plugins build.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if commonOpts.UserPlatform == platform.Unknown {
+				return fmt.Errorf("must explicitly select a cluster platform")
+			}
+			if err := validateComponentRenderOptions(opts); err != nil {
+				return err
+			}
+			if err := validateSchedProfileOptions(profOpts); err != nil {
+				return err
+			}
+			mf, err := sched.GetExtraProfileManifests()
+			if err != nil {
+				return err
+			}
+			schedRenderOpts := schedRenderOptionsFrom(commonOpts)
+			objs := mf.Render(schedRenderOpts).ToObjects()
+
+			rendered, err := sched.RenderExtraProfileConfig(env.Log, commonOpts.UserPlatform, schedRenderOpts)
+			if err != nil {
+				return err
+			}
+
+			if profOpts.existingConfig != "" {
+				existing, err := os.ReadFile(profOpts.existingConfig)
+				if err != nil {
+					return err
+				}
+				merged, err := schedupdate.MergeConfigProfiles(existing, rendered)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(profOpts.mergedConfig, merged, 0644); err != nil {
+					return err
+				}
+				env.Log.Info("scheduler config merged", "existing", profOpts.existingConfig, "merged", profOpts.mergedConfig)
+			} else {
+				if err := env.EnsureClient(); err != nil {
+					return err
+				}
+				cm, err := mergeSchedulerConfigMap(env, profOpts, rendered)
+				if err != nil {
+					return err
+				}
+				objs = append(objs, cm)
+			}
+			return writeComponents(opts, []manifests.Component{
+				{Name: ComponentSchedulerProfile, Objects: objs},
+			})
+		},
+		Args: cobra.NoArgs,
+	}
+	render.Flags().StringVar(&profOpts.existingConfig, "existing-config", "", "path of the existing kube-scheduler configuration file to merge the profile into.")
+	render.Flags().StringVar(&profOpts.mergedConfig, "merged-config", "", "path to write the merged kube-scheduler configuration file to. Required with --existing-config.")
+	render.Flags().StringVar(&profOpts.existingConfigMap, "existing-configmap", "", "namespace/name of the ConfigMap holding the existing kube-scheduler configuration, read from the cluster. The updated ConfigMap is rendered.")
+	render.Flags().StringVar(&profOpts.configMapKey, "configmap-key", manifests.SchedulerConfigFileName, "data key holding the configuration in the existing ConfigMap.")
+	return render
+}
+
+func validateSchedProfileOptions(opts *schedProfileOptions) error {
+	if (opts.existingConfig == "") == (opts.existingConfigMap == "") {
+		return fmt.Errorf("exactly one of --existing-config and --existing-configmap is required")
+	}
+	if opts.existingConfig != "" && opts.mergedConfig == "" {
+		return fmt.Errorf("--existing-config requires --merged-config")
+	}
+	if opts.existingConfigMap != "" {
+		if _, _, err := splitNamespacedName(opts.existingConfigMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mergeSchedulerConfigMap(env *deployer.Environment, opts *schedProfileOptions, rendered []byte) (*corev1.ConfigMap, error) {
+	namespace, name, err := splitNamespacedName(opts.existingConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{}
+	if err := env.Cli.Get(env.Ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		return nil, err
+	}
+	if err := schedupdate.SchedulerConfigMergeProfiles(cm, opts.configMapKey, rendered); err != nil {
+		return nil, err
+	}
+
+	// render a clean object, ready to be applied
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        cm.Name,
+			Namespace:   cm.Namespace,
+			Labels:      cm.Labels,
+			Annotations: cm.Annotations,
+		},
+		Data:       cm.Data,
+		BinaryData: cm.BinaryData,
+	}, nil
+}
+
+func splitNamespacedName(val string) (string, string, error) {
+	namespace, name, ok := strings.Cut(val, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("malformed namespaced name %q, expected namespace/name", val)
+	}
+	return namespace, name, nil
+}
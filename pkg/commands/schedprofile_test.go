@@ -0,0 +1,215 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+func TestValidateSchedProfileOptions(t *testing.T) {
+	type testCase struct {
+		name        string
+		opts        schedProfileOptions
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:        "no existing configuration",
+			expectError: true,
+		},
+		{
+			name: "existing config",
+			opts: schedProfileOptions{existingConfig: "config.yaml", mergedConfig: "merged.yaml"},
+		},
+		{
+			name:        "existing config without merged config",
+			opts:        schedProfileOptions{existingConfig: "config.yaml"},
+			expectError: true,
+		},
+		{
+			name: "existing configmap",
+			opts: schedProfileOptions{existingConfigMap: "kube-system/scheduler-config"},
+		},
+		{
+			name:        "both existing config and configmap",
+			opts:        schedProfileOptions{existingConfig: "config.yaml", mergedConfig: "merged.yaml", existingConfigMap: "kube-system/scheduler-config"},
+			expectError: true,
+		},
+		{
+			name:        "malformed existing configmap",
+			opts:        schedProfileOptions{existingConfigMap: "scheduler-config"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSchedProfileOptions(&tc.opts)
+			if tc.expectError != (err != nil) {
+				t.Errorf("expected error %v got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestSplitNamespacedName(t *testing.T) {
+	type testCase struct {
+		val               string
+		expectedNamespace string
+		expectedName      string
+		expectError       bool
+	}
+
+	testCases := []testCase{
+		{
+			val:               "kube-system/scheduler-config",
+			expectedNamespace: "kube-system",
+			expectedName:      "scheduler-config",
+		},
+		{
+			val:         "",
+			expectError: true,
+		},
+		{
+			val:         "scheduler-config",
+			expectError: true,
+		},
+		{
+			val:         "/scheduler-config",
+			expectError: true,
+		},
+		{
+			val:         "kube-system/",
+			expectError: true,
+		},
+		{
+			val:         "kube-system/scheduler/config",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			namespace, name, err := splitNamespacedName(tc.val)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error %v got %v", tc.expectError, err)
+			}
+			if namespace != tc.expectedNamespace || name != tc.expectedName {
+				t.Errorf("got %q %q expected %q %q", namespace, name, tc.expectedNamespace, tc.expectedName)
+			}
+		})
+	}
+}
+
+func TestMergeSchedulerConfigMap(t *testing.T) {
+	existing := &corev1.ConfigMap{}
+	existing.Namespace = "kube-system"
+	existing.Name = "scheduler-config"
+	existing.Labels = map[string]string{"app": "kube-scheduler"}
+	existing.ResourceVersion = "42"
+	existing.Data = map[string]string{
+		manifests.SchedulerConfigFileName: `apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+profiles:
+- schedulerName: default-scheduler
+`,
+	}
+	rendered := []byte(`apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+profiles:
+- schedulerName: topology-aware-scheduler
+  pluginConfig:
+  - args: {}
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+`)
+
+	type testCase struct {
+		name        string
+		opts        schedProfileOptions
+		initObjs    []client.Object
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "merged",
+			opts:     schedProfileOptions{existingConfigMap: "kube-system/scheduler-config", configMapKey: manifests.SchedulerConfigFileName},
+			initObjs: []client.Object{existing},
+		},
+		{
+			name:        "missing configmap",
+			opts:        schedProfileOptions{existingConfigMap: "kube-system/scheduler-config", configMapKey: manifests.SchedulerConfigFileName},
+			expectError: true,
+		},
+		{
+			name:        "missing key",
+			opts:        schedProfileOptions{existingConfigMap: "kube-system/scheduler-config", configMapKey: "missing.yaml"},
+			initObjs:    []client.Object{existing},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := &deployer.Environment{
+				Ctx: context.Background(),
+				Cli: fake.NewClientBuilder().WithObjects(tc.initObjs...).Build(),
+				Log: logr.Discard(),
+			}
+			cm, err := mergeSchedulerConfigMap(env, &tc.opts, rendered)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error %v got %v", tc.expectError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if cm.Namespace != existing.Namespace || cm.Name != existing.Name {
+				t.Errorf("got %s/%s expected %s/%s", cm.Namespace, cm.Name, existing.Namespace, existing.Name)
+			}
+			if cm.Labels["app"] != "kube-scheduler" {
+				t.Errorf("labels not preserved: %v", cm.Labels)
+			}
+			if cm.ResourceVersion != "" {
+				t.Errorf("rendered object has resource version %q", cm.ResourceVersion)
+			}
+			data := cm.Data[manifests.SchedulerConfigFileName]
+			for _, schedulerName := range []string{"default-scheduler", "topology-aware-scheduler"} {
+				if !strings.Contains(data, "schedulerName: "+schedulerName) {
+					t.Errorf("merged configuration misses the profile %q:\n%s", schedulerName, data)
+				}
+			}
+		})
+	}
+}
@@ -24,6 +24,9 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/k8stopologyawareschedwg/deployer/pkg/clientutil/nodes"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deploy"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
@@ -88,7 +91,7 @@ func validateCluster(cmd *cobra.Command, env *deployer.Environment, commonOpts *
 		return err
 	}
 
-	nodeList, err := nodes.GetWorkers(env)
+	nodeList, err := getNodesToValidate(env, commonOpts)
 	if err != nil {
 		return err
 	}
@@ -101,6 +104,18 @@ func validateCluster(cmd *cobra.Command, env *deployer.Environment, commonOpts *
 	return nil
 }
 
+// validate the same nodes the updater would run on
+func getNodesToValidate(env *deployer.Environment, commonOpts *deploy.Options) ([]corev1.Node, error) {
+	if commonOpts.UpdaterNodeSelector == nil {
+		return nodes.GetWorkers(env)
+	}
+	sel, err := metav1.LabelSelectorAsSelector(commonOpts.UpdaterNodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	return nodes.GetBySelector(env, sel)
+}
+
 // we need undecorated output, so we need to use fmt.Printf here. log packages add no value.
 func printValidationResults(items []validator.ValidationResult, logger logr.Logger, outputMode ValidateOutputMode) {
 	if len(items) == 0 {
@@ -45,37 +45,62 @@ func OnCluster(env *deployer.Environment, commonOpts *Options) error {
 	}
 
 	env.Log.Info("detection", "platform", commonOpts.ClusterPlatform, "reason", reason, "version", commonOpts.ClusterVersion, "source", source)
-	if err := api.Deploy(env, api.Options{
-		Platform: commonOpts.ClusterPlatform,
-	}); err != nil {
+	if err := api.Deploy(env, APIOptionsFrom(commonOpts)); err != nil {
 		return err
 	}
 	if err := updaters.Deploy(env, commonOpts.UpdaterType, updaters.Options{
-		Platform:        commonOpts.ClusterPlatform,
-		PlatformVersion: commonOpts.ClusterVersion,
-		WaitCompletion:  commonOpts.WaitCompletion,
-		RTEConfigData:   commonOpts.RTEConfigData,
-		DaemonSet:       DaemonSetOptionsFrom(commonOpts),
-		EnableCRIHooks:  commonOpts.UpdaterCRIHooksEnable,
+		Platform:                  commonOpts.ClusterPlatform,
+		PlatformVersion:           commonOpts.ClusterVersion,
+		WaitCompletion:            commonOpts.WaitCompletion,
+		RTEConfigData:             commonOpts.RTEConfigData,
+		DaemonSet:                 DaemonSetOptionsFrom(commonOpts),
+		EnableCRIHooks:            commonOpts.UpdaterCRIHooksEnable,
+		MachineConfigPoolSelector: commonOpts.MachineConfigPoolSelector,
+		Namespace:                 commonOpts.UpdaterNamespace,
+		Metadata:                  MetadataOptionsFrom(commonOpts),
+		ImagePull:                 ImagePullOptionsFrom(commonOpts),
+		Resume:                    commonOpts.Resume,
 	}); err != nil {
 		return err
 	}
-	if err := sched.Deploy(env, sched.Options{
-		Platform:          commonOpts.ClusterPlatform,
-		WaitCompletion:    commonOpts.WaitCompletion,
-		Replicas:          int32(commonOpts.Replicas),
-		RTEConfigData:     commonOpts.RTEConfigData,
-		PullIfNotPresent:  commonOpts.PullIfNotPresent,
-		ProfileName:       commonOpts.SchedProfileName,
-		CacheResyncPeriod: commonOpts.SchedResyncPeriod,
-		CtrlPlaneAffinity: commonOpts.SchedCtrlPlaneAffinity,
-		Verbose:           commonOpts.SchedVerbose,
-	}); err != nil {
+	if err := sched.Deploy(env, SchedOptionsFrom(commonOpts)); err != nil {
 		return err
 	}
 	return nil
 }
 
+// SchedOptionsFrom returns the scheduler options for the detected cluster platform and version.
+func SchedOptionsFrom(commonOpts *Options) sched.Options {
+	return sched.Options{
+		Platform:               commonOpts.ClusterPlatform,
+		PlatformVersion:        commonOpts.ClusterVersion,
+		WaitCompletion:         commonOpts.WaitCompletion,
+		Replicas:               int32(commonOpts.Replicas),
+		RTEConfigData:          commonOpts.RTEConfigData,
+		PullIfNotPresent:       commonOpts.PullIfNotPresent,
+		ProfileName:            commonOpts.SchedProfileName,
+		CacheResyncPeriod:      commonOpts.SchedResyncPeriod,
+		CacheResyncMethod:      commonOpts.SchedCacheResyncMethod,
+		CacheForeignPodsDetect: commonOpts.SchedCacheForeignPodsDetect,
+		CacheInformerMode:      commonOpts.SchedCacheInformerMode,
+		ScoringStrategy:        commonOpts.SchedScoringStrategy,
+		Profiles:               commonOpts.SchedProfiles,
+		PodDisruptionBudget:    commonOpts.SchedPodDisruptionBudget,
+		LeaderElection:         commonOpts.SchedLeaderElection,
+		LeaseName:              commonOpts.SchedLeaseName,
+		LeaseNamespace:         commonOpts.SchedLeaseNamespace,
+		CtrlPlaneAffinity:      commonOpts.SchedCtrlPlaneAffinity,
+		Namespace:              commonOpts.SchedNamespace,
+		Metadata:               MetadataOptionsFrom(commonOpts),
+		ImagePull:              ImagePullOptionsFrom(commonOpts),
+		Images:                 commonOpts.Images,
+		Resources:              SchedResourcesOptionsFrom(commonOpts),
+		PriorityClassName:      commonOpts.PriorityClassName,
+		Verbose:                commonOpts.SchedVerbose,
+		Resume:                 commonOpts.Resume,
+	}
+}
+
 func DaemonSetOptionsFrom(commonOpts *Options) objectupdate.DaemonSetOptions {
 	return objectupdate.DaemonSetOptions{
 		PullIfNotPresent:   commonOpts.PullIfNotPresent,
@@ -83,5 +108,49 @@ func DaemonSetOptionsFrom(commonOpts *Options) objectupdate.DaemonSetOptions {
 		NotificationEnable: commonOpts.UpdaterNotifEnable,
 		UpdateInterval:     commonOpts.UpdaterSyncPeriod,
 		Verbose:            commonOpts.UpdaterVerbose,
+		NodeSelector:       commonOpts.UpdaterNodeSelector,
+		Tolerations:        commonOpts.UpdaterTolerations,
+		PriorityClassName:  commonOpts.PriorityClassName,
+		Resources: objectupdate.ResourcesOptions{
+			Containers: commonOpts.ContainerResources,
+			Guaranteed: commonOpts.UpdaterGuaranteedQoS,
+		},
+		Images: commonOpts.Images,
+	}
+}
+
+// APIOptionsFrom sets the PriorityClass to be created along the API, if requested.
+func APIOptionsFrom(commonOpts *Options) api.Options {
+	opts := api.Options{
+		Platform: commonOpts.ClusterPlatform,
+		Resume:   commonOpts.Resume,
+		Metadata: MetadataOptionsFrom(commonOpts),
+	}
+	if commonOpts.CreatePriorityClass {
+		opts.PriorityClassName = commonOpts.PriorityClassName
+		opts.PriorityClassValue = commonOpts.PriorityClassValue
+	}
+	return opts
+}
+
+func SchedResourcesOptionsFrom(commonOpts *Options) objectupdate.ResourcesOptions {
+	return objectupdate.ResourcesOptions{
+		Containers: commonOpts.ContainerResources,
+	}
+}
+
+func ImagePullOptionsFrom(commonOpts *Options) objectupdate.ImagePullOptions {
+	return objectupdate.ImagePullOptions{
+		Secrets:          commonOpts.ImagePullSecrets,
+		DockerConfigData: commonOpts.ImagePullSecretData,
+	}
+}
+
+func MetadataOptionsFrom(commonOpts *Options) objectupdate.MetadataOptions {
+	return objectupdate.MetadataOptions{
+		Labels:      commonOpts.CommonLabels,
+		Annotations: commonOpts.CommonAnnotations,
+		NamePrefix:  commonOpts.NamePrefix,
+		NameSuffix:  commonOpts.NameSuffix,
 	}
 }
@@ -19,7 +19,12 @@ package deploy
 import (
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 )
 
 type Options struct {
@@ -34,13 +39,56 @@ type Options struct {
 	UpdaterCRIHooksEnable  bool
 	UpdaterSyncPeriod      time.Duration
 	UpdaterVerbose         int
+	UpdaterNodeSelector    *metav1.LabelSelector
+	UpdaterTolerations     []corev1.Toleration
 	SchedProfileName       string
 	SchedResyncPeriod      time.Duration
 	SchedVerbose           int
 	SchedCtrlPlaneAffinity bool
-	WaitInterval           time.Duration
-	WaitTimeout            time.Duration
-	ClusterPlatform        platform.Platform
-	ClusterVersion         platform.Version
-	WaitCompletion         bool
+	SchedNamespace         string
+	UpdaterNamespace       string
+	CommonLabels           map[string]string
+	CommonAnnotations      map[string]string
+	NamePrefix             string
+	NameSuffix             string
+	// ContainerResources maps the container names to their resources
+	ContainerResources   map[string]corev1.ResourceRequirements
+	UpdaterGuaranteedQoS bool
+	WaitInterval         time.Duration
+	WaitTimeout          time.Duration
+	ClusterPlatform      platform.Platform
+	ClusterVersion       platform.Version
+	WaitCompletion       bool
+	Resume               bool
+	// MachineConfigPoolSelector is used only on OpenShift
+	MachineConfigPoolSelector *metav1.LabelSelector
+	// SchedPodDisruptionBudget is always enabled if Replicas > 1
+	SchedPodDisruptionBudget bool
+	// SchedLeaderElection is always enabled if Replicas > 1. The lease defaults
+	// to the scheduler name and namespace.
+	SchedLeaderElection bool
+	SchedLeaseName      string
+	SchedLeaseNamespace string
+	// PriorityClassName is set on all the pods; the PriorityClass itself is
+	// rendered only if CreatePriorityClass is set.
+	PriorityClassName   string
+	PriorityClassValue  int32
+	CreatePriorityClass bool
+	// ImagePullSecrets are used by all the pods; ImagePullSecretData, if set,
+	// is the docker config to create the first of them from.
+	ImagePullSecrets    []string
+	ImagePullSecretData []byte
+	// ImageRegistryMirror replaces the registry of all the images
+	ImageRegistryMirror string
+	// Images are the result of the defaults, environment, file and flags
+	Images       images.ImageSet
+	ImagesUseSHA bool
+	// SchedScoringStrategy unset keeps the plugin default
+	SchedScoringStrategy *manifests.ScoringStrategyParams
+	// the scheduler cache knobs left empty keep the plugin defaults
+	SchedCacheResyncMethod      string
+	SchedCacheForeignPodsDetect string
+	SchedCacheInformerMode      string
+	// SchedProfiles, if any, replace the SchedProfileName profile
+	SchedProfiles []manifests.ConfigParams
 }
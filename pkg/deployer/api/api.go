@@ -20,15 +20,22 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 	apimanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/api"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 	apiwait "github.com/k8stopologyawareschedwg/deployer/pkg/objectwait/api"
 )
 
 type Options struct {
 	Platform platform.Platform
+	Resume   bool
+	Metadata objectupdate.MetadataOptions
+	// PriorityClassName, if not empty, is the PriorityClass to create along the API
+	PriorityClassName  string
+	PriorityClassValue int32
 }
 
 func SetupNamespace(plat platform.Platform) (*corev1.Namespace, string, error) {
@@ -44,10 +51,18 @@ func Deploy(env *deployer.Environment, opts Options) error {
 	if err != nil {
 		return err
 	}
+	mf, err = mf.Render(apimanifests.RenderOptions{
+		Metadata:           opts.Metadata,
+		PriorityClassName:  opts.PriorityClassName,
+		PriorityClassValue: opts.PriorityClassValue,
+	})
+	if err != nil {
+		return err
+	}
 	env.Log.V(3).Info("API manifests loaded")
 
 	for _, wo := range apiwait.Creatable(mf, env.Cli, env.Log) {
-		if err := env.CreateObject(wo.Obj); err != nil {
+		if err := env.CreateOrResumeObject(wo.Obj, opts.Resume); err != nil {
 			return err
 		}
 
@@ -74,11 +89,23 @@ func Remove(env *deployer.Environment, opts Options) error {
 	if err != nil {
 		return err
 	}
+	mf, err = mf.Render(apimanifests.RenderOptions{
+		Metadata:           opts.Metadata,
+		PriorityClassName:  opts.PriorityClassName,
+		PriorityClassValue: opts.PriorityClassValue,
+	})
+	if err != nil {
+		return err
+	}
 	env.Log.V(3).Info("API manifests loaded")
 
+	var errs deployer.ObjectErrors
 	for _, wo := range apiwait.Deletable(mf, env.Cli, env.Log) {
 		err = env.DeleteObject(wo.Obj)
 		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				errs.Add(wo.Obj, err)
+			}
 			continue
 		}
 
@@ -89,9 +116,14 @@ func Remove(env *deployer.Environment, opts Options) error {
 		err = wo.Wait(env.Ctx)
 		if err != nil {
 			env.Log.Info("failed to wait for removal", "error", err)
+			errs.Add(wo.Obj, err)
 		}
 	}
 
+	if len(errs) > 0 {
+		env.Log.Info("partially removed topology-aware-scheduling API", "failures", len(errs))
+		return errs.AsError()
+	}
 	env.Log.Info("removed topology-aware-scheduling API!")
 	return nil
 }
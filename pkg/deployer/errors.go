@@ -0,0 +1,94 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package deployer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectError reports a failure processing a specific object.
+// Errors not related to any object have empty Kind and Name.
+type ObjectError struct {
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Reason    string `json:"error"`
+}
+
+func NewObjectError(obj client.Object, err error) ObjectError {
+	return ObjectError{
+		Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Reason:    err.Error(),
+	}
+}
+
+func (oe ObjectError) Error() string {
+	if oe.Kind == "" && oe.Name == "" {
+		return oe.Reason
+	}
+	if oe.Namespace == "" {
+		return fmt.Sprintf("%s %s: %s", oe.Kind, oe.Name, oe.Reason)
+	}
+	return fmt.Sprintf("%s %s/%s: %s", oe.Kind, oe.Namespace, oe.Name, oe.Reason)
+}
+
+// ObjectErrors aggregates the failures processing a set of objects.
+type ObjectErrors []ObjectError
+
+func (oes ObjectErrors) Error() string {
+	items := make([]string, 0, len(oes))
+	for _, oe := range oes {
+		items = append(items, oe.Error())
+	}
+	return fmt.Sprintf("failed to process %d object(s): %s", len(oes), strings.Join(items, "; "))
+}
+
+func (oes *ObjectErrors) Add(obj client.Object, err error) {
+	*oes = append(*oes, NewObjectError(obj, err))
+}
+
+// Merge appends the given error, flattening it if it is an ObjectErrors or an ObjectError.
+func (oes *ObjectErrors) Merge(err error) {
+	if err == nil {
+		return
+	}
+	var other ObjectErrors
+	if errors.As(err, &other) {
+		*oes = append(*oes, other...)
+		return
+	}
+	var single ObjectError
+	if errors.As(err, &single) {
+		*oes = append(*oes, single)
+		return
+	}
+	*oes = append(*oes, ObjectError{Reason: err.Error()})
+}
+
+// AsError returns nil if no failures were recorded, avoiding non-nil interfaces holding nil values.
+func (oes ObjectErrors) AsError() error {
+	if len(oes) == 0 {
+		return nil
+	}
+	return oes
+}
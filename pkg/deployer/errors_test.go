@@ -0,0 +1,53 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package deployer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestObjectErrors(t *testing.T) {
+	var errs ObjectErrors
+	if errs.AsError() != nil {
+		t.Fatalf("empty errors should be nil")
+	}
+
+	errs.Add(newConfigMap("foo", nil), fmt.Errorf("forbidden"))
+
+	var other ObjectErrors
+	other.Add(newConfigMap("bar", nil), fmt.Errorf("timeout"))
+	errs.Merge(fmt.Errorf("while removing: %w", other.AsError()))
+	errs.Merge(fmt.Errorf("generic failure"))
+	errs.Merge(nil)
+
+	if len(errs) != 3 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	err := errs.AsError()
+	var got ObjectErrors
+	if !errors.As(err, &got) {
+		t.Fatalf("cannot unwrap aggregated errors from %v", err)
+	}
+
+	expected := "failed to process 3 object(s): ConfigMap default/foo: forbidden; ConfigMap default/bar: timeout; generic failure"
+	if err.Error() != expected {
+		t.Errorf("got=%q expected=%q", err.Error(), expected)
+	}
+}
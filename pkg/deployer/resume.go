@@ -0,0 +1,152 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package deployer
+
+import (
+	"fmt"
+	"reflect"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// CreateOrResumeObject creates the given object. If resume is requested, checks first if the object
+// already exists on the cluster: if so, and it matches the rendered object, the creation is skipped.
+func (env Environment) CreateOrResumeObject(obj client.Object, resume bool) error {
+	if resume {
+		found, err := env.ResumeObject(obj)
+		if err != nil {
+			return err
+		}
+		if found {
+			return nil
+		}
+	}
+	return env.CreateObject(obj)
+}
+
+// ResumeObject returns true if the given object already exists on the cluster and matches
+// the rendered object, false if the object does not exist. An object which exists but differs
+// from the rendered one is reported as error, because we can't safely take it over.
+func (env Environment) ResumeObject(obj client.Object) (bool, error) {
+	objKind := obj.GetObjectKind().GroupVersionKind().Kind // shortcut
+	// decoding into a prefilled object would keep the rendered values missing on the cluster
+	gvk, err := apiutil.GVKForObject(obj, env.Cli.Scheme())
+	if err != nil {
+		return false, err
+	}
+	newObj, err := env.Cli.Scheme().New(gvk)
+	if err != nil {
+		return false, err
+	}
+	existing, ok := newObj.(client.Object)
+	if !ok {
+		return false, fmt.Errorf("cannot create object %s %q", objKind, obj.GetName())
+	}
+	err = env.Cli.Get(env.Ctx, client.ObjectKeyFromObject(obj), existing)
+	if k8serrors.IsNotFound(err) {
+		env.Log.V(3).Info("missing", "kind", objKind, "name", obj.GetName())
+		return false, nil
+	}
+	if err != nil {
+		env.Log.Info("error resuming", "kind", objKind, "name", obj.GetName(), "error", err)
+		return false, err
+	}
+	path, err := findMismatch(obj, existing)
+	if err != nil {
+		return false, err
+	}
+	if path != "" {
+		return false, fmt.Errorf("cannot resume: %s %q exists but differs from the rendered object at %q", objKind, obj.GetName(), path)
+	}
+	env.Log.Info("skipped existing", "kind", objKind, "name", obj.GetName())
+	return true, nil
+}
+
+// findMismatch returns the path of the first field set in the rendered object which
+// doesn't match the existing object, or empty string if the existing object matches.
+// The existing object may have more fields set (e.g. defaulted by the apiserver).
+func findMismatch(rendered, existing runtime.Object) (string, error) {
+	renderedData, err := runtime.DefaultUnstructuredConverter.ToUnstructured(rendered)
+	if err != nil {
+		return "", err
+	}
+	existingData, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return "", err
+	}
+	// server-managed fields, we should never compare these
+	delete(renderedData, "status")
+	if meta, ok := renderedData["metadata"].(map[string]interface{}); ok {
+		delete(meta, "creationTimestamp")
+		delete(meta, "resourceVersion")
+		delete(meta, "uid")
+		delete(meta, "generation")
+		delete(meta, "managedFields")
+	}
+	return mismatchPath("", renderedData, existingData), nil
+}
+
+func mismatchPath(path string, rendered, existing interface{}) string {
+	switch rv := rendered.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}:
+		ev, ok := existing.(map[string]interface{})
+		if !ok {
+			if len(rv) == 0 && existing == nil {
+				return ""
+			}
+			return path
+		}
+		for key, val := range rv {
+			if mp := mismatchPath(path+"."+key, val, ev[key]); mp != "" {
+				return mp
+			}
+		}
+		return ""
+	case []interface{}:
+		ev, ok := existing.([]interface{})
+		if !ok {
+			if len(rv) == 0 && existing == nil {
+				return ""
+			}
+			return path
+		}
+		if len(rv) != len(ev) {
+			return path
+		}
+		for idx := range rv {
+			if mp := mismatchPath(fmt.Sprintf("%s[%d]", path, idx), rv[idx], ev[idx]); mp != "" {
+				return mp
+			}
+		}
+		return ""
+	default:
+		if reflect.DeepEqual(rendered, existing) {
+			return ""
+		}
+		// json quirk: numbers may be decoded with different types
+		if fmt.Sprintf("%v", rendered) == fmt.Sprintf("%v", existing) {
+			return ""
+		}
+		return path
+	}
+}
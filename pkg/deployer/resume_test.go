@@ -0,0 +1,225 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResumeObject(t *testing.T) {
+	type testCase struct {
+		name          string
+		initObjs      []client.Object
+		obj           client.Object
+		expectedFound bool
+		expectedError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:          "missing",
+			obj:           newConfigMap("foo", map[string]string{"key": "value"}),
+			expectedFound: false,
+		},
+		{
+			name:          "matching",
+			initObjs:      []client.Object{newConfigMap("foo", map[string]string{"key": "value"})},
+			obj:           newConfigMap("foo", map[string]string{"key": "value"}),
+			expectedFound: true,
+		},
+		{
+			name: "matching with extra fields",
+			initObjs: []client.Object{
+				withLabels(newConfigMap("foo", map[string]string{"key": "value", "extra": "data"}), map[string]string{"app": "test"}),
+			},
+			obj:           newConfigMap("foo", map[string]string{"key": "value"}),
+			expectedFound: true,
+		},
+		{
+			name:          "differing",
+			initObjs:      []client.Object{newConfigMap("foo", map[string]string{"key": "other"})},
+			obj:           newConfigMap("foo", map[string]string{"key": "value"}),
+			expectedError: true,
+		},
+		{
+			name:          "differing labels",
+			initObjs:      []client.Object{newConfigMap("foo", map[string]string{"key": "value"})},
+			obj:           withLabels(newConfigMap("foo", map[string]string{"key": "value"}), map[string]string{"app": "test"}),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := Environment{
+				Ctx: context.TODO(),
+				Cli: fake.NewClientBuilder().WithObjects(tc.initObjs...).Build(),
+				Log: testr.New(t),
+			}
+			found, err := env.ResumeObject(tc.obj)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("unexpected success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if found != tc.expectedFound {
+				t.Errorf("found=%v expected=%v", found, tc.expectedFound)
+			}
+		})
+	}
+}
+
+func TestResumeObjectDrifted(t *testing.T) {
+	type testCase struct {
+		name     string
+		existing client.Object
+		obj      client.Object
+	}
+
+	testCases := []testCase{
+		{
+			name:     "missing label",
+			existing: newConfigMap("foo", map[string]string{"key": "value"}),
+			obj:      withLabels(newConfigMap("foo", map[string]string{"key": "value"}), map[string]string{"app": "test"}),
+		},
+		{
+			name:     "missing args",
+			existing: newDeployment("foo", nil),
+			obj:      newDeployment("foo", []string{"--verbose=4"}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := Environment{
+				Ctx: context.TODO(),
+				Cli: decodingClient{fake.NewClientBuilder().WithObjects(tc.existing).Build()},
+				Log: testr.New(t),
+			}
+			found, err := env.ResumeObject(tc.obj)
+			if err == nil {
+				t.Fatalf("drifted object resumed: found=%v", found)
+			}
+		})
+	}
+}
+
+// decodingClient decodes the live objects into the given ones without clearing them first, like the real client
+type decodingClient struct {
+	client.Client
+}
+
+func (dc decodingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, dc.Scheme())
+	if err != nil {
+		return err
+	}
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(gvk)
+	if err := dc.Client.Get(ctx, key, live, opts...); err != nil {
+		return err
+	}
+	data, err := live.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, obj)
+}
+
+func TestCreateOrResumeObject(t *testing.T) {
+	existing := newConfigMap("foo", map[string]string{"key": "value"})
+	env := Environment{
+		Ctx: context.TODO(),
+		Cli: fake.NewClientBuilder().WithObjects(existing).Build(),
+		Log: testr.New(t),
+	}
+
+	err := env.CreateOrResumeObject(newConfigMap("foo", map[string]string{"key": "value"}), false)
+	if err == nil {
+		t.Fatalf("unexpected success creating an existing object without resume")
+	}
+
+	for _, obj := range []client.Object{
+		newConfigMap("foo", map[string]string{"key": "value"}),
+		newConfigMap("bar", map[string]string{"key": "value"}),
+	} {
+		if err := env.CreateOrResumeObject(obj, true); err != nil {
+			t.Fatalf("unexpected failure resuming %q: %v", obj.GetName(), err)
+		}
+	}
+
+	cm := corev1.ConfigMap{}
+	if err := env.Cli.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "bar"}, &cm); err != nil {
+		t.Fatalf("missing object not created: %v", err)
+	}
+}
+
+func newConfigMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Data: data,
+	}
+}
+
+func newDeployment(name string, args []string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: name, Image: "quay.io/foo/bar:v1", Args: args}},
+				},
+			},
+		},
+	}
+}
+
+func withLabels(cm *corev1.ConfigMap, labels map[string]string) *corev1.ConfigMap {
+	cm.Labels = labels
+	return cm
+}
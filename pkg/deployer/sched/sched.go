@@ -17,31 +17,89 @@
 package sched
 
 import (
-	"fmt"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	schedmanifests "github.com/k8stopologyawareschedwg/deployer/pkg/manifests/sched"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 	schedwait "github.com/k8stopologyawareschedwg/deployer/pkg/objectwait/sched"
 )
 
 type Options struct {
-	Platform          platform.Platform
+	Platform platform.Platform
+	// PlatformVersion selects the scheduler configuration API version, if given
+	PlatformVersion   platform.Version
 	WaitCompletion    bool
 	Replicas          int32
 	ProfileName       string
 	RTEConfigData     string
 	PullIfNotPresent  bool
 	CacheResyncPeriod time.Duration
-	CtrlPlaneAffinity bool
-	Verbose           int
+	// the cache knobs left empty keep the plugin defaults
+	CacheResyncMethod      string
+	CacheForeignPodsDetect string
+	CacheInformerMode      string
+	ScoringStrategy        *manifests.ScoringStrategyParams
+	Profiles               []manifests.ConfigParams
+	CtrlPlaneAffinity      bool
+	Verbose                int
+	Resume                 bool
+	// Namespace overrides the platform default namespace
+	Namespace string
+	Metadata  objectupdate.MetadataOptions
+	Resources objectupdate.ResourcesOptions
+	// PodDisruptionBudget is always enabled if Replicas > 1
+	PodDisruptionBudget bool
+	// LeaderElection is always enabled if Replicas > 1
+	LeaderElection    bool
+	LeaseName         string
+	LeaseNamespace    string
+	PriorityClassName string
+	ImagePull         objectupdate.ImagePullOptions
+	Images            images.ImageSet
 }
 
-func SetupNamespace(plat platform.Platform) (*corev1.Namespace, string, error) {
-	return nil, "", fmt.Errorf("not yet implemented")
+// RenderOptions returns the options to render the scheduler manifests
+func (opts Options) RenderOptions() schedmanifests.RenderOptions {
+	return schedmanifests.RenderOptions{
+		ProfileName:            opts.ProfileName,
+		Replicas:               opts.Replicas,
+		PullIfNotPresent:       opts.PullIfNotPresent,
+		CacheResyncPeriod:      opts.CacheResyncPeriod,
+		CacheResyncMethod:      opts.CacheResyncMethod,
+		CacheForeignPodsDetect: opts.CacheForeignPodsDetect,
+		CacheInformerMode:      opts.CacheInformerMode,
+		ScoringStrategy:        opts.ScoringStrategy,
+		Profiles:               opts.Profiles,
+		PlatformVersion:        opts.PlatformVersion,
+		CtrlPlaneAffinity:      opts.CtrlPlaneAffinity,
+		Verbose:                opts.Verbose,
+		Namespace:              opts.Namespace,
+		Metadata:               opts.Metadata,
+		Resources:              opts.Resources,
+		PriorityClassName:      opts.PriorityClassName,
+		ImagePull:              opts.ImagePull,
+		Images:                 opts.Images,
+		PodDisruptionBudget:    opts.PodDisruptionBudget,
+		LeaderElection:         opts.LeaderElection,
+		LeaseName:              opts.LeaseName,
+		LeaseNamespace:         opts.LeaseNamespace,
+	}
+}
+
+func SetupNamespace(plat platform.Platform, namespace string) (*corev1.Namespace, string, error) {
+	ns, err := manifests.Namespace(manifests.ComponentSchedulerPlugin)
+	if err != nil {
+		return nil, "", err
+	}
+	schedmanifests.UpdateNamespace(ns, plat, namespace)
+	return ns, ns.Name, nil
 }
 
 func Deploy(env *deployer.Environment, opts Options) error {
@@ -54,21 +112,14 @@ func Deploy(env *deployer.Environment, opts Options) error {
 		return err
 	}
 
-	mf, err = mf.Render(env.Log, schedmanifests.RenderOptions{
-		ProfileName:       opts.ProfileName,
-		Replicas:          opts.Replicas,
-		PullIfNotPresent:  opts.PullIfNotPresent,
-		CacheResyncPeriod: opts.CacheResyncPeriod,
-		CtrlPlaneAffinity: opts.CtrlPlaneAffinity,
-		Verbose:           opts.Verbose,
-	})
+	mf, err = mf.Render(env.Log, opts.RenderOptions())
 	if err != nil {
 		return err
 	}
 	env.Log.V(3).Info("manifests loaded")
 
 	for _, wo := range schedwait.Creatable(mf, env.Cli, env.Log) {
-		if err := env.CreateObject(wo.Obj); err != nil {
+		if err := env.CreateOrResumeObject(wo.Obj, opts.Resume); err != nil {
 			return err
 		}
 
@@ -96,22 +147,19 @@ func Remove(env *deployer.Environment, opts Options) error {
 		return err
 	}
 
-	mf, err = mf.Render(env.Log, schedmanifests.RenderOptions{
-		ProfileName:       opts.ProfileName,
-		Replicas:          opts.Replicas,
-		PullIfNotPresent:  opts.PullIfNotPresent,
-		CacheResyncPeriod: opts.CacheResyncPeriod,
-		CtrlPlaneAffinity: opts.CtrlPlaneAffinity,
-		Verbose:           opts.Verbose,
-	})
+	mf, err = mf.Render(env.Log, opts.RenderOptions())
 	if err != nil {
 		return err
 	}
 	env.Log.V(3).Info("manifests loaded")
 
+	var errs deployer.ObjectErrors
 	for _, wo := range schedwait.Deletable(mf, env.Cli, env.Log) {
 		err = env.DeleteObject(wo.Obj)
 		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				errs.Add(wo.Obj, err)
+			}
 			continue
 		}
 
@@ -122,9 +170,14 @@ func Remove(env *deployer.Environment, opts Options) error {
 		err = wo.Wait(env.Ctx)
 		if err != nil {
 			env.Log.Info("failed to wait for removal", "error", err)
+			errs.Add(wo.Obj, err)
 		}
 	}
 
+	if len(errs) > 0 {
+		env.Log.Info("partially removed topology-aware-scheduling scheduler plugin", "failures", len(errs))
+		return errs.AsError()
+	}
 	env.Log.Info("removed topology-aware-scheduling scheduler plugin")
 	return nil
 }
@@ -0,0 +1,104 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package updaters
+
+import (
+	"fmt"
+
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/clientutil/machineconfigpools"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/clientutil/nodes"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+)
+
+// SetupMachineConfigPools checks the MachineConfigPools selected by the MachineConfigPoolSelector
+// exist and cover all the nodes the updater DaemonSet will run on. If the DaemonSet has no
+// node selector, it is derived from the selected pools.
+func SetupMachineConfigPools(env *deployer.Environment, updaterType string, opts *Options) error {
+	if updaterType != RTE || opts.Platform != platform.OpenShift || opts.MachineConfigPoolSelector == nil {
+		return nil
+	}
+
+	mcLabels := opts.MachineConfigPoolSelector.MatchLabels
+	mcps, err := machineconfigpools.GetByMachineConfigLabels(env, mcLabels)
+	if err != nil {
+		return err
+	}
+	if len(mcps) == 0 {
+		return fmt.Errorf("no MachineConfigPool selects the labels %v", mcLabels)
+	}
+	for _, mcp := range mcps {
+		env.Log.Info("selected", "machineConfigPool", mcp.Name)
+	}
+
+	if opts.DaemonSet.NodeSelector == nil {
+		nodeSelector, err := nodeSelectorFromMachineConfigPools(mcps)
+		if err != nil {
+			return err
+		}
+		env.Log.Info("derived updater node selector", "nodeSelector", nodeSelector.MatchLabels)
+		opts.DaemonSet.NodeSelector = nodeSelector
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(opts.DaemonSet.NodeSelector)
+	if err != nil {
+		return err
+	}
+	nodeList, err := nodes.GetBySelector(env, sel)
+	if err != nil {
+		return err
+	}
+	if len(nodeList) == 0 {
+		return fmt.Errorf("no node matches the updater node selector %q", sel.String())
+	}
+	for idx := range nodeList {
+		node := &nodeList[idx]
+		mcp, err := machineconfigpools.FindByNode(mcps, node)
+		if err != nil {
+			return err
+		}
+		if mcp == nil {
+			return fmt.Errorf("node %q matches the updater node selector but none of the selected MachineConfigPools", node.Name)
+		}
+	}
+	return nil
+}
+
+func nodeSelectorFromMachineConfigPools(mcps []machineconfigv1.MachineConfigPool) (*metav1.LabelSelector, error) {
+	var nodeSelector *metav1.LabelSelector
+	for _, mcp := range mcps {
+		if mcp.Spec.NodeSelector == nil {
+			return nil, fmt.Errorf("MachineConfigPool %q has no node selector", mcp.Name)
+		}
+		if len(mcp.Spec.NodeSelector.MatchExpressions) > 0 {
+			return nil, fmt.Errorf("cannot derive the updater node selector from MachineConfigPool %q: match expressions are not supported", mcp.Name)
+		}
+		if nodeSelector == nil {
+			nodeSelector = mcp.Spec.NodeSelector.DeepCopy()
+			continue
+		}
+		if !equality.Semantic.DeepEqual(nodeSelector.MatchLabels, mcp.Spec.NodeSelector.MatchLabels) {
+			return nil, fmt.Errorf("selected MachineConfigPools have different node selectors, cannot derive the updater node selector")
+		}
+	}
+	return nodeSelector, nil
+}
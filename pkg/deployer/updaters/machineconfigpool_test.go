@@ -0,0 +1,180 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package updaters
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+)
+
+func TestSetupMachineConfigPools(t *testing.T) {
+	type testCase struct {
+		name                 string
+		initObjs             []client.Object
+		mcpSelector          *metav1.LabelSelector
+		nodeSelector         *metav1.LabelSelector
+		expectedNodeSelector *metav1.LabelSelector
+		expectError          string
+	}
+
+	workerSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+	}
+	cnfSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"node-role.kubernetes.io/worker-cnf": ""},
+	}
+
+	testCases := []testCase{
+		{
+			name: "nothing to do without selector",
+		},
+		{
+			name: "derive the node selector from the pool",
+			initObjs: []client.Object{
+				newMCP("worker", "worker", workerSelector),
+				newNode("worker-0", "worker"),
+				newNode("worker-1", "worker"),
+			},
+			mcpSelector:          newMCPSelector("worker"),
+			expectedNodeSelector: workerSelector,
+		},
+		{
+			name: "explicit node selector within the pool",
+			initObjs: []client.Object{
+				newMCP("worker", "worker", workerSelector),
+				newMCP("worker-cnf", "worker-cnf", cnfSelector),
+				newNode("worker-0", "worker"),
+				newNode("worker-1", "worker", "worker-cnf"),
+			},
+			mcpSelector:          newMCPSelector("worker-cnf"),
+			nodeSelector:         cnfSelector,
+			expectedNodeSelector: cnfSelector,
+		},
+		{
+			name: "missing pool",
+			initObjs: []client.Object{
+				newMCP("worker", "worker", workerSelector),
+			},
+			mcpSelector: newMCPSelector("worker-cnf"),
+			expectError: "no MachineConfigPool selects",
+		},
+		{
+			name: "node selector outside the pool",
+			initObjs: []client.Object{
+				newMCP("worker-cnf", "worker-cnf", cnfSelector),
+				newNode("worker-0", "worker"),
+				newNode("worker-1", "worker", "worker-cnf"),
+			},
+			mcpSelector:  newMCPSelector("worker-cnf"),
+			nodeSelector: workerSelector,
+			expectError:  `node "worker-0"`,
+		},
+		{
+			name: "no node in the pool",
+			initObjs: []client.Object{
+				newMCP("worker-cnf", "worker-cnf", cnfSelector),
+				newNode("worker-0", "worker"),
+			},
+			mcpSelector: newMCPSelector("worker-cnf"),
+			expectError: "no node matches",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatalf("cannot setup the scheme: %v", err)
+			}
+			if err := machineconfigv1.Install(scheme); err != nil {
+				t.Fatalf("cannot setup the scheme: %v", err)
+			}
+			env := &deployer.Environment{
+				Ctx: context.TODO(),
+				Cli: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.initObjs...).Build(),
+				Log: testr.New(t),
+			}
+			opts := Options{
+				Platform:                  platform.OpenShift,
+				MachineConfigPoolSelector: tc.mcpSelector,
+			}
+			opts.DaemonSet.NodeSelector = tc.nodeSelector
+
+			err := SetupMachineConfigPools(env, RTE, &opts)
+			if tc.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectError) {
+					t.Fatalf("unexpected error: got %v expected to contain %q", err, tc.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if !reflect.DeepEqual(opts.DaemonSet.NodeSelector, tc.expectedNodeSelector) {
+				t.Errorf("node selector mismatch: got %v expected %v", opts.DaemonSet.NodeSelector, tc.expectedNodeSelector)
+			}
+		})
+	}
+}
+
+func newMCPSelector(role string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{"machineconfiguration.openshift.io/role": role},
+	}
+}
+
+func newMCP(name, role string, nodeSelector *metav1.LabelSelector) *machineconfigv1.MachineConfigPool {
+	return &machineconfigv1.MachineConfigPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: machineconfigv1.MachineConfigPoolSpec{
+			MachineConfigSelector: newMCPSelector(role),
+			NodeSelector:          nodeSelector,
+		},
+	}
+}
+
+func newNode(name string, roles ...string) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{},
+		},
+	}
+	for _, role := range roles {
+		node.Labels["node-role.kubernetes.io/"+role] = ""
+	}
+	return node
+}
@@ -109,9 +109,12 @@ func getDeletableObjects(env *deployer.Environment, opts Options, updaterType, n
 
 func rteOptionsFrom(opts Options, namespace string) rtemanifests.RenderOptions {
 	return rtemanifests.RenderOptions{
-		ConfigData: opts.RTEConfigData,
-		DaemonSet:  opts.DaemonSet,
-		Namespace:  namespace,
+		ConfigData:                opts.RTEConfigData,
+		DaemonSet:                 opts.DaemonSet,
+		MachineConfigPoolSelector: opts.MachineConfigPoolSelector,
+		Namespace:                 namespace,
+		Metadata:                  opts.Metadata,
+		ImagePull:                 opts.ImagePull,
 	}
 }
 
@@ -119,5 +122,7 @@ func nfdOptionsFrom(opts Options, namespace string) nfdmanifests.RenderOptions {
 	return nfdmanifests.RenderOptions{
 		Namespace: namespace,
 		DaemonSet: opts.DaemonSet,
+		Metadata:  opts.Metadata,
+		ImagePull: opts.ImagePull,
 	}
 }
@@ -21,6 +21,9 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
@@ -42,16 +45,29 @@ type Options struct {
 	RTEConfigData   string
 	DaemonSet       objectupdate.DaemonSetOptions
 	EnableCRIHooks  bool
+	Resume          bool
+	// Namespace overrides the default namespace of the updater
+	Namespace string
+	Metadata  objectupdate.MetadataOptions
+	ImagePull objectupdate.ImagePullOptions
+	// MachineConfigPoolSelector sets the labels of the MachineConfig, thus selecting the pools
+	// which will pick it. OpenShift only.
+	MachineConfigPoolSelector *metav1.LabelSelector
 }
 
 func Deploy(env *deployer.Environment, updaterType string, opts Options) error {
 	env = env.WithName(updaterType)
 	env.Log.Info("deploying topology-aware-scheduling topology updater")
 
-	ns, namespace, err := SetupNamespace(updaterType)
+	ns, namespace, err := SetupNamespace(updaterType, opts.Namespace)
 	if err != nil {
 		return err
 	}
+	objectupdate.Metadata([]client.Object{ns}, opts.Metadata)
+
+	if err := SetupMachineConfigPools(env, updaterType, &opts); err != nil {
+		return err
+	}
 
 	objs, err := getCreatableObjects(env, opts, updaterType, namespace)
 	if err != nil {
@@ -63,7 +79,7 @@ func Deploy(env *deployer.Environment, updaterType string, opts Options) error {
 	objs = append([]objectwait.WaitableObject{{Obj: ns}}, objs...)
 
 	for _, wo := range objs {
-		if err := env.CreateObject(wo.Obj); err != nil {
+		if err := env.CreateOrResumeObject(wo.Obj, opts.Resume); err != nil {
 			return err
 		}
 
@@ -86,11 +102,11 @@ func Remove(env *deployer.Environment, updaterType string, opts Options) error {
 	env = env.WithName(updaterType)
 	env.Log.Info("removing topology-aware-scheduling topology updater")
 
-	ns, err := manifests.Namespace(updaterTypeAsComponent(updaterType))
+	ns, namespace, err := SetupNamespace(updaterType, opts.Namespace)
 	if err != nil {
 		return err
 	}
-	namespace := ns.Name
+	objectupdate.Metadata([]client.Object{ns}, opts.Metadata)
 
 	objs, err := getDeletableObjects(env, opts, updaterType, namespace)
 	if err != nil {
@@ -103,9 +119,13 @@ func Remove(env *deployer.Environment, updaterType string, opts Options) error {
 		Obj:  ns,
 		Wait: func(ctx context.Context) error { return wait.With(env.Cli, env.Log).ForNamespaceDeleted(ctx, ns.Name) },
 	})
+	var errs deployer.ObjectErrors
 	for _, wo := range objs {
 		err = env.DeleteObject(wo.Obj)
 		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				errs.Add(wo.Obj, err)
+			}
 			continue
 		}
 
@@ -116,18 +136,26 @@ func Remove(env *deployer.Environment, updaterType string, opts Options) error {
 		err = wo.Wait(env.Ctx)
 		if err != nil {
 			env.Log.Info("failed to wait for removal", "error", err)
+			errs.Add(wo.Obj, err)
 		}
 	}
 
+	if len(errs) > 0 {
+		env.Log.Info("partially removed topology-aware-scheduling topology updater", "failures", len(errs))
+		return errs.AsError()
+	}
 	env.Log.Info("removed topology-aware-scheduling topology updater!")
 	return nil
 }
 
-func SetupNamespace(updaterType string) (*corev1.Namespace, string, error) {
+func SetupNamespace(updaterType, namespace string) (*corev1.Namespace, string, error) {
 	ns, err := manifests.Namespace(updaterTypeAsComponent(updaterType))
 	if err != nil {
 		return nil, "", err
 	}
+	if namespace != "" {
+		ns.Name = namespace
+	}
 	return ns, ns.Name, nil
 }
 
@@ -0,0 +1,140 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/clientutil/machineconfigpools"
+)
+
+// rolling out a MachineConfig reboots every node in the pool, one at a time,
+// so the regular poll timeout is almost never enough.
+const MinMachineConfigPoolPollTimeout = 30 * time.Minute
+
+// ForMachineConfigPools returns a copy of the Waiter whose timeout
+// is suitable to wait for a MachineConfigPool rollout.
+func (wt Waiter) ForMachineConfigPools() *Waiter {
+	if wt.PollTimeout < MinMachineConfigPoolPollTimeout {
+		wt.PollTimeout = MinMachineConfigPoolPollTimeout
+	}
+	return &wt
+}
+
+// ForMachineConfigPoolUpdated waits until all the pools selecting the given
+// MachineConfig rendered a configuration which includes it and all their
+// machines are updated to that configuration.
+func (wt Waiter) ForMachineConfigPoolUpdated(ctx context.Context, mc *machineconfigv1.MachineConfig) error {
+	return wt.forMachineConfigPoolUpdated(ctx, mc, true)
+}
+
+// ForMachineConfigPoolUpdatedAfterDeletion waits until all the pools which
+// used to select the given MachineConfig rendered a configuration which
+// does not include it anymore and all their machines are updated to that configuration.
+func (wt Waiter) ForMachineConfigPoolUpdatedAfterDeletion(ctx context.Context, mc *machineconfigv1.MachineConfig) error {
+	return wt.forMachineConfigPoolUpdated(ctx, mc, false)
+}
+
+func (wt Waiter) forMachineConfigPoolUpdated(ctx context.Context, mc *machineconfigv1.MachineConfig, expectSource bool) error {
+	log := wt.Log.WithValues("machineConfig", mc.Name)
+	log.Info("wait for the machine config pools to be updated")
+	return k8swait.PollImmediate(wt.PollInterval, wt.PollTimeout, func() (bool, error) {
+		mcps, err := wt.selectingMachineConfigPools(ctx, mc)
+		if err != nil {
+			log.Info("failed to get the machine config pools", "error", err)
+			return false, err
+		}
+		if len(mcps) == 0 {
+			return false, fmt.Errorf("no MachineConfigPool selects MachineConfig %q", mc.Name)
+		}
+
+		updated := true
+		for idx := range mcps {
+			mcp := &mcps[idx]
+			if err := checkMachineConfigPoolDegraded(mcp); err != nil {
+				log.Info("machine config pool degraded", "pool", mcp.Name, "error", err)
+				return false, err
+			}
+			if hasSource(mcp.Status.Configuration, mc.Name) != expectSource {
+				log.Info("machine config pool not yet rendered", "pool", mcp.Name, "rendered", mcp.Status.Configuration.Name)
+				updated = false
+				continue
+			}
+			if !IsMachineConfigPoolUpdated(mcp) {
+				log.Info("machine config pool not updated",
+					"pool", mcp.Name,
+					"machines", mcp.Status.MachineCount,
+					"updated", mcp.Status.UpdatedMachineCount,
+					"ready", mcp.Status.ReadyMachineCount)
+				updated = false
+				continue
+			}
+			log.Info("machine config pool updated", "pool", mcp.Name, "rendered", mcp.Status.Configuration.Name)
+		}
+		return updated, nil
+	})
+}
+
+func (wt Waiter) selectingMachineConfigPools(ctx context.Context, mc *machineconfigv1.MachineConfig) ([]machineconfigv1.MachineConfigPool, error) {
+	mcpList := machineconfigv1.MachineConfigPoolList{}
+	err := wt.Cli.List(ctx, &mcpList)
+	if err != nil {
+		return nil, err
+	}
+	return machineconfigpools.FilterByMachineConfigLabels(mcpList.Items, mc.Labels)
+}
+
+// IsMachineConfigPoolUpdated returns true if all the machines in the pool
+// are running the configuration currently rendered for the pool.
+func IsMachineConfigPoolUpdated(mcp *machineconfigv1.MachineConfigPool) bool {
+	return mcp.Status.ObservedGeneration >= mcp.Generation &&
+		machineconfigv1.IsMachineConfigPoolConditionTrue(mcp.Status.Conditions, machineconfigv1.MachineConfigPoolUpdated) &&
+		mcp.Status.UpdatedMachineCount == mcp.Status.MachineCount
+}
+
+func checkMachineConfigPoolDegraded(mcp *machineconfigv1.MachineConfigPool) error {
+	for _, condType := range []machineconfigv1.MachineConfigPoolConditionType{
+		machineconfigv1.MachineConfigPoolNodeDegraded,
+		machineconfigv1.MachineConfigPoolRenderDegraded,
+	} {
+		cond := machineconfigv1.GetMachineConfigPoolCondition(mcp.Status, condType)
+		if cond == nil || cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		return fmt.Errorf("MachineConfigPool %q is %s (%d degraded machines): %s", mcp.Name, condType, mcp.Status.DegradedMachineCount, cond.Message)
+	}
+	if mcp.Status.DegradedMachineCount > 0 {
+		return fmt.Errorf("MachineConfigPool %q has %d degraded machines", mcp.Name, mcp.Status.DegradedMachineCount)
+	}
+	return nil
+}
+
+func hasSource(conf machineconfigv1.MachineConfigPoolStatusConfiguration, mcName string) bool {
+	for _, src := range conf.Source {
+		if src.Name == mcName {
+			return true
+		}
+	}
+	return false
+}
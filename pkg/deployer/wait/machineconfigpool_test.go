@@ -0,0 +1,193 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package wait
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+
+	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestForMachineConfigPools(t *testing.T) {
+	wt := With(nil, testr.New(t)).Timeout(time.Minute).ForMachineConfigPools()
+	if wt.PollTimeout != MinMachineConfigPoolPollTimeout {
+		t.Errorf("timeout not raised: got %v expected %v", wt.PollTimeout, MinMachineConfigPoolPollTimeout)
+	}
+	wt = With(nil, testr.New(t)).Timeout(time.Hour).ForMachineConfigPools()
+	if wt.PollTimeout != time.Hour {
+		t.Errorf("timeout lowered: got %v expected %v", wt.PollTimeout, time.Hour)
+	}
+}
+
+func TestForMachineConfigPoolUpdated(t *testing.T) {
+	type testCase struct {
+		name          string
+		initObjs      []client.Object
+		afterDeletion bool
+		expectError   string
+		expectTimeout bool
+	}
+
+	mc := &machineconfigv1.MachineConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "50-rte-selinux",
+			Labels: map[string]string{
+				"machineconfiguration.openshift.io/role": "worker",
+			},
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name: "updated with the machine config",
+			initObjs: []client.Object{
+				newMachineConfigPool("worker", "worker", []string{"00-worker", "50-rte-selinux"}, 3, 3, 0, nil),
+				newMachineConfigPool("master", "master", []string{"00-master"}, 3, 1, 0, nil),
+			},
+		},
+		{
+			name: "not yet rendered",
+			initObjs: []client.Object{
+				newMachineConfigPool("worker", "worker", []string{"00-worker"}, 3, 3, 0, nil),
+			},
+			expectTimeout: true,
+		},
+		{
+			name: "rolling out",
+			initObjs: []client.Object{
+				newMachineConfigPool("worker", "worker", []string{"00-worker", "50-rte-selinux"}, 3, 1, 0, nil),
+			},
+			expectTimeout: true,
+		},
+		{
+			name: "degraded node",
+			initObjs: []client.Object{
+				newMachineConfigPool("worker", "worker", []string{"00-worker", "50-rte-selinux"}, 3, 1, 1, &machineconfigv1.MachineConfigPoolCondition{
+					Type:    machineconfigv1.MachineConfigPoolNodeDegraded,
+					Status:  corev1.ConditionTrue,
+					Message: "Node worker-0 is reporting: failed to apply",
+				}),
+			},
+			expectError: "failed to apply",
+		},
+		{
+			name: "no pool selects the machine config",
+			initObjs: []client.Object{
+				newMachineConfigPool("master", "master", []string{"00-master"}, 3, 3, 0, nil),
+			},
+			expectError: "no MachineConfigPool selects",
+		},
+		{
+			name: "updated after deletion",
+			initObjs: []client.Object{
+				newMachineConfigPool("worker", "worker", []string{"00-worker"}, 3, 3, 0, nil),
+			},
+			afterDeletion: true,
+		},
+		{
+			name: "still rendered after deletion",
+			initObjs: []client.Object{
+				newMachineConfigPool("worker", "worker", []string{"00-worker", "50-rte-selinux"}, 3, 3, 0, nil),
+			},
+			afterDeletion: true,
+			expectTimeout: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := machineconfigv1.Install(scheme); err != nil {
+				t.Fatalf("cannot install the machineconfig scheme: %v", err)
+			}
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.initObjs...).Build()
+
+			wt := With(cli, testr.New(t)).Interval(100 * time.Millisecond).Timeout(500 * time.Millisecond)
+			var err error
+			if tc.afterDeletion {
+				err = wt.ForMachineConfigPoolUpdatedAfterDeletion(context.TODO(), mc)
+			} else {
+				err = wt.ForMachineConfigPoolUpdated(context.TODO(), mc)
+			}
+
+			if tc.expectError == "" && !tc.expectTimeout {
+				if err != nil {
+					t.Errorf("unexpected failure: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("unexpected success")
+			}
+			if tc.expectError != "" && !strings.Contains(err.Error(), tc.expectError) {
+				t.Errorf("unexpected error: %v expected to contain %q", err, tc.expectError)
+			}
+		})
+	}
+}
+
+func newMachineConfigPool(name, role string, sources []string, machines, updated, degraded int32, cond *machineconfigv1.MachineConfigPoolCondition) *machineconfigv1.MachineConfigPool {
+	mcp := &machineconfigv1.MachineConfigPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: machineconfigv1.MachineConfigPoolSpec{
+			MachineConfigSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"machineconfiguration.openshift.io/role": role,
+				},
+			},
+		},
+		Status: machineconfigv1.MachineConfigPoolStatus{
+			Configuration: machineconfigv1.MachineConfigPoolStatusConfiguration{
+				ObjectReference: corev1.ObjectReference{
+					Name: "rendered-" + name,
+				},
+			},
+			MachineCount:         machines,
+			UpdatedMachineCount:  updated,
+			DegradedMachineCount: degraded,
+		},
+	}
+	for _, src := range sources {
+		mcp.Status.Configuration.Source = append(mcp.Status.Configuration.Source, corev1.ObjectReference{Name: src})
+	}
+	updatedStatus := corev1.ConditionFalse
+	if updated == machines {
+		updatedStatus = corev1.ConditionTrue
+	}
+	mcp.Status.Conditions = append(mcp.Status.Conditions, machineconfigv1.MachineConfigPoolCondition{
+		Type:   machineconfigv1.MachineConfigPoolUpdated,
+		Status: updatedStatus,
+	})
+	if cond != nil {
+		mcp.Status.Conditions = append(mcp.Status.Conditions, *cond)
+	}
+	return mcp
+}
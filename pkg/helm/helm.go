@@ -0,0 +1,265 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+const (
+	ChartFileName     = "Chart.yaml"
+	ValuesFileName    = "values.yaml"
+	TemplatesDirName  = "templates"
+	ChartAPIVersionV2 = "v2"
+)
+
+// Chart is the minimal subset of the chart metadata we need to generate
+type Chart struct {
+	APIVersion  string `json:"apiVersion"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Axis is a knob which changes the structure of the rendered objects, so it cannot be
+// expressed with a Placeholder. Templates get a branch for each of the choices.
+type Axis struct {
+	// Key is the path of the value, like "updater.type"
+	Key     string
+	Choices []interface{}
+	// Expr, if given, is the template expression compared to the choices instead of the
+	// value, so the branch can depend on other values too.
+	Expr string
+}
+
+// Placeholder is a knob rendered using a sentinel value, which is then replaced
+// in the templates by a reference to the value.
+type Placeholder struct {
+	// Key is the path of the value, like "scheduler.replicas"
+	Key string
+	// Sentinel is the value the knob is rendered with. It must be unique in the rendered objects,
+	// unless Prefix is given, in which case only Prefix+Sentinel must be unique.
+	Sentinel string
+	Prefix   string
+	// Optional placeholders drop the whole line if the value is empty
+	Optional bool
+}
+
+// Combination maps the axis keys to the choice to render
+type Combination map[string]interface{}
+
+type RenderFunc func(comb Combination) ([]manifests.Component, error)
+
+type Generator struct {
+	Axes         []Axis
+	Placeholders []Placeholder
+	Render       RenderFunc
+}
+
+// WriteChart renders all the combinations of the generator axes and writes a chart in dir
+// whose templates select the right combination at install time.
+func WriteChart(dir string, chart Chart, values map[string]interface{}, gen Generator) error {
+	tmpls, err := gen.Templates()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, TemplatesDirName), 0755); err != nil {
+		return err
+	}
+	if err := writeYAML(filepath.Join(dir, ChartFileName), chart); err != nil {
+		return err
+	}
+	if err := writeYAML(filepath.Join(dir, ValuesFileName), values); err != nil {
+		return err
+	}
+	for _, tmpl := range tmpls {
+		tmplPath := filepath.Join(dir, TemplatesDirName, tmpl.Path)
+		if err := os.MkdirAll(filepath.Dir(tmplPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(tmplPath, []byte(tmpl.Data), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Template is a chart template, with its path relative to the templates directory
+type Template struct {
+	Path string
+	Data string
+}
+
+// Templates renders all the combinations of the generator axes and returns the templates,
+// one per object, in the order they are first rendered.
+func (gen Generator) Templates() ([]Template, error) {
+	combs := gen.combinations()
+	var paths []string
+	rendered := make(map[string][]string) // path -> text for each combination
+	for idx, comb := range combs {
+		comps, err := gen.Render(comb)
+		if err != nil {
+			return nil, err
+		}
+		for _, comp := range comps {
+			fileNames, err := manifests.ObjectFileNames(comp.Objects)
+			if err != nil {
+				return nil, err
+			}
+			for objIdx, obj := range comp.Objects {
+				data, err := manifests.SerializeObjectToData(obj)
+				if err != nil {
+					return nil, err
+				}
+				path := filepath.Join(comp.Name, fileNames[objIdx])
+				if _, ok := rendered[path]; !ok {
+					paths = append(paths, path)
+					rendered[path] = make([]string, len(combs))
+				}
+				rendered[path][idx] = gen.replacePlaceholders(string(data))
+			}
+		}
+	}
+
+	tmpls := make([]Template, 0, len(paths))
+	for _, path := range paths {
+		tmpls = append(tmpls, Template{
+			Path: path,
+			Data: gen.branch(rendered[path], make([]int, len(gen.Axes)), 0),
+		})
+	}
+	return tmpls, nil
+}
+
+// combinations returns all the combinations of the axes. The index of a combination
+// is computed from the choice indexes using the axes as digits of a mixed radix number.
+func (gen Generator) combinations() []Combination {
+	combs := []Combination{{}}
+	for _, axis := range gen.Axes {
+		var next []Combination
+		for _, comb := range combs {
+			for _, choice := range axis.Choices {
+				nc := Combination{}
+				for key, val := range comb {
+					nc[key] = val
+				}
+				nc[axis.Key] = choice
+				next = append(next, nc)
+			}
+		}
+		combs = next
+	}
+	return combs
+}
+
+func (gen Generator) combinationIndex(choices []int) int {
+	idx := 0
+	for axisIdx, axis := range gen.Axes {
+		idx = idx*len(axis.Choices) + choices[axisIdx]
+	}
+	return idx
+}
+
+// branch returns the template for the given text of each combination, branching
+// only on the axes, starting from axisIdx, which actually change the text.
+func (gen Generator) branch(texts []string, choices []int, axisIdx int) string {
+	if axisIdx == len(gen.Axes) {
+		return texts[gen.combinationIndex(choices)]
+	}
+
+	axis := gen.Axes[axisIdx]
+	subs := make([]string, len(axis.Choices))
+	for choiceIdx := range axis.Choices {
+		choices[axisIdx] = choiceIdx
+		subs[choiceIdx] = gen.branch(texts, choices, axisIdx+1)
+	}
+	choices[axisIdx] = 0
+
+	same := true
+	for _, sub := range subs[1:] {
+		if sub != subs[0] {
+			same = false
+			break
+		}
+	}
+	if same {
+		return subs[0]
+	}
+
+	ref := valueRef(axis.Key)
+	if axis.Expr != "" {
+		ref = "(" + axis.Expr + ")"
+	}
+	var sb strings.Builder
+	for choiceIdx, choice := range axis.Choices {
+		keyword := "else if"
+		if choiceIdx == 0 {
+			keyword = "if"
+		}
+		fmt.Fprintf(&sb, "{{- %s eq %s %s }}\n%s", keyword, ref, choiceLiteral(choice), subs[choiceIdx])
+	}
+	fmt.Fprintf(&sb, "{{- else }}\n{{- fail \"unsupported value for %s\" }}\n{{- end }}\n", axis.Key)
+	return sb.String()
+}
+
+func (gen Generator) replacePlaceholders(text string) string {
+	for _, ph := range gen.Placeholders {
+		match := ph.Prefix + ph.Sentinel
+		repl := ph.Prefix + "{{ " + valueRef(ph.Key) + " }}"
+		if !ph.Optional {
+			text = strings.ReplaceAll(text, match, repl)
+			continue
+		}
+		lines := strings.Split(text, "\n")
+		for idx, line := range lines {
+			if !strings.Contains(line, match) {
+				continue
+			}
+			lines[idx] = "{{- if " + valueRef(ph.Key) + " }}\n" + strings.ReplaceAll(line, match, repl) + "\n{{- end }}"
+		}
+		text = strings.Join(lines, "\n")
+	}
+	return text
+}
+
+func valueRef(key string) string {
+	return ".Values." + key
+}
+
+func choiceLiteral(choice interface{}) string {
+	if s, ok := choice.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", choice)
+}
+
+func writeYAML(path string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
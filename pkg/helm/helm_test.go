@@ -0,0 +1,232 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+func TestTemplates(t *testing.T) {
+	type testCase struct {
+		name     string
+		values   map[string]interface{}
+		expected string
+	}
+
+	gen := Generator{
+		Axes: []Axis{
+			{Key: "mode", Choices: []interface{}{"a", "b"}},
+			{Key: "flag", Choices: []interface{}{true, false}},
+		},
+		Placeholders: []Placeholder{
+			{Key: "count", Sentinel: "918273641"},
+			{Key: "period", Sentinel: "918273642", Optional: true},
+		},
+		Render: func(comb Combination) ([]manifests.Component, error) {
+			return []manifests.Component{
+				{Name: "test", Objects: renderTestObjects(comb["mode"].(string), comb["flag"].(bool), "918273641", "918273642")},
+			}, nil
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name:     "a with flag",
+			values:   map[string]interface{}{"mode": "a", "flag": true, "count": "3", "period": "10s"},
+			expected: renderTestYAML(t, "a", true, "3", "10s"),
+		},
+		{
+			name:     "b without flag",
+			values:   map[string]interface{}{"mode": "b", "flag": false, "count": "5", "period": "10s"},
+			expected: renderTestYAML(t, "b", false, "5", "10s"),
+		},
+		{
+			name:     "optional value missing",
+			values:   map[string]interface{}{"mode": "b", "flag": true, "count": "5", "period": ""},
+			expected: renderTestYAML(t, "b", true, "5", ""),
+		},
+	}
+
+	tmpls, err := gen.Templates()
+	if err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			for _, tmpl := range tmpls {
+				got, err := executeTemplate(tmpl, tc.values)
+				if err != nil {
+					t.Fatalf("cannot execute %q: %v\n%s", tmpl.Path, err, tmpl.Data)
+				}
+				if strings.TrimSpace(got) == "" {
+					continue
+				}
+				sb.WriteString(strings.TrimLeft(got, "\n"))
+			}
+			if sb.String() != tc.expected {
+				t.Errorf("output mismatch:\ngot=%v\nexpected=%v\n", sb.String(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestTemplatesUnsupportedValue(t *testing.T) {
+	gen := Generator{
+		Axes: []Axis{
+			{Key: "mode", Choices: []interface{}{"a", "b"}},
+		},
+		Render: func(comb Combination) ([]manifests.Component, error) {
+			return []manifests.Component{
+				{Name: "test", Objects: renderTestObjects(comb["mode"].(string), true, "1", "1")},
+			}, nil
+		},
+	}
+	tmpls, err := gen.Templates()
+	if err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+	_, err = executeTemplate(tmpls[0], map[string]interface{}{"mode": "c"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported value for mode") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTemplatesAxisExpr(t *testing.T) {
+	type testCase struct {
+		name     string
+		values   map[string]interface{}
+		expected string
+	}
+
+	gen := Generator{
+		Axes: []Axis{
+			{Key: "flag", Choices: []interface{}{true, false}, Expr: "or .Values.flag (gt (int .Values.count) 1)"},
+		},
+		Render: func(comb Combination) ([]manifests.Component, error) {
+			return []manifests.Component{
+				{Name: "test", Objects: renderTestObjects("b", comb["flag"].(bool), "1", "")},
+			}, nil
+		},
+	}
+
+	testCases := []testCase{
+		{
+			name:     "flag set",
+			values:   map[string]interface{}{"flag": true, "count": 1},
+			expected: renderTestYAML(t, "b", true, "1", ""),
+		},
+		{
+			name:     "flag forced by the count",
+			values:   map[string]interface{}{"flag": false, "count": 3},
+			expected: renderTestYAML(t, "b", true, "1", ""),
+		},
+		{
+			name:     "flag unset",
+			values:   map[string]interface{}{"flag": false, "count": 1},
+			expected: renderTestYAML(t, "b", false, "1", ""),
+		},
+	}
+
+	tmpls, err := gen.Templates()
+	if err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := executeTemplate(tmpls[0], tc.values)
+			if err != nil {
+				t.Fatalf("cannot execute %q: %v\n%s", tmpls[0].Path, err, tmpls[0].Data)
+			}
+			if strings.TrimLeft(got, "\n") != tc.expected {
+				t.Errorf("output mismatch:\ngot=%v\nexpected=%v\n", got, tc.expected)
+			}
+		})
+	}
+}
+
+func renderTestObjects(mode string, flag bool, count, period string) []client.Object {
+	data := map[string]string{
+		"mode":   mode,
+		"config": fmt.Sprintf("count: %s\n", count),
+	}
+	if period != "" {
+		data["config"] += fmt.Sprintf("period: %s\n", period)
+	}
+	if flag {
+		data["flag"] = "enabled"
+	}
+	objs := []client.Object{
+		&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "common", Namespace: "default"},
+			Data:       data,
+		},
+	}
+	if mode == "a" {
+		objs = append(objs, &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "only-a", Namespace: "default"},
+		})
+	}
+	return objs
+}
+
+func renderTestYAML(t *testing.T, mode string, flag bool, count, period string) string {
+	var sb strings.Builder
+	for _, obj := range renderTestObjects(mode, flag, count, period) {
+		data, err := manifests.SerializeObjectToData(obj)
+		if err != nil {
+			t.Fatalf("cannot serialize: %v", err)
+		}
+		sb.Write(data)
+	}
+	return sb.String()
+}
+
+// executeTemplate approximates what helm does, providing only the functions we use
+func executeTemplate(tmpl Template, values map[string]interface{}) (string, error) {
+	tt, err := template.New(tmpl.Path).Funcs(template.FuncMap{
+		"fail": func(msg string) (string, error) {
+			return "", errors.New(msg)
+		},
+		// like the sprig function available in helm, good enough for the tests
+		"int": func(v interface{}) int {
+			val, _ := v.(int)
+			return val
+		},
+	}).Parse(tmpl.Data)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = tt.Execute(&sb, map[string]interface{}{"Values": values})
+	return sb.String(), err
+}
@@ -29,3 +29,8 @@ const (
 	NodeFeatureDiscoveryDefaultImageSHA      = "registry.k8s.io/nfd/node-feature-discovery@sha256:5bfcae5ea107987520822b5d8bedd715b4a2951ab9ec975d387b40ef9e3a638f"
 	ResourceTopologyExporterDefaultImageSHA  = "quay.io/k8stopologyawareschedwg/resource-topology-exporter@sha256:577e68f5a8956a55a5cc0e2bc7183cd13d79f95c0a8feaeca98535b4de1e9116"
 )
+
+const (
+	// PauseDefaultImage runs the placeholder containers
+	PauseDefaultImage = "gcr.io/google_containers/pause-amd64:3.0"
+)
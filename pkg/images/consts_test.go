@@ -20,16 +20,12 @@ import "testing"
 
 func TestImageURISanity(t *testing.T) {
 	// TODO: check these are valid pullSpecs
-	if SchedulerPluginSchedulerImage == "" {
-		t.Fatalf("invalid Scheduler Plugin Scheduler Image pull URL")
-	}
-	if SchedulerPluginControllerImage == "" {
-		t.Fatalf("invalid Scheduler Plugin Controller Image pull URL")
-	}
-	if ResourceTopologyExporterImage == "" {
-		t.Fatalf("invalid Resource Topology Exporter Image pull URL")
-	}
-	if NodeFeatureDiscoveryImage == "" {
-		t.Fatalf("invalid Node Feature Discovery Image pull URL")
+	for _, useSHA := range []bool{false, true} {
+		is := Defaults(useSHA)
+		for _, key := range Keys() {
+			if image, src := is.Get(key); image == "" || src != SourceDefault {
+				t.Fatalf("invalid %s image pull URL (sha=%v): %q source %q", key, useSHA, image, src)
+			}
+		}
 	}
 }
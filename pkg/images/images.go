@@ -16,46 +16,275 @@
 
 package images
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strings"
 
-func init() {
-	_, ok := os.LookupEnv("TAS_IMAGES_USE_SHA")
-	SetDefaults(ok)
-	Setup(os.LookupEnv)
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	KeyScheduler                = "scheduler"
+	KeyController               = "controller"
+	KeyResourceTopologyExporter = "resourceTopologyExporter"
+	KeyNodeFeatureDiscovery     = "nodeFeatureDiscovery"
+	KeyPause                    = "pause"
+)
+
+// Source tells where an image of an ImageSet comes from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceFlag    Source = "flag"
+)
+
+// EnvUseSHA makes Defaults use the SHA256 pullspecs
+const EnvUseSHA = "TAS_IMAGES_USE_SHA"
+
+// EnvVars maps the image keys to the environment variables overriding them
+var EnvVars = map[string]string{
+	KeyScheduler:                "TAS_SCHEDULER_PLUGIN_IMAGE",
+	KeyController:               "TAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE",
+	KeyResourceTopologyExporter: "TAS_RESOURCE_EXPORTER_IMAGE",
+	KeyNodeFeatureDiscovery:     "TAS_NODE_FEATURE_DISCOVERY_IMAGE",
+	KeyPause:                    "TAS_PAUSE_IMAGE",
 }
 
-func SetDefaults(useSHA bool) {
+// ImageSet holds the images of all the components. Empty fields are unset:
+// WithDefaults fills them. The zero value is ready to use.
+type ImageSet struct {
+	Scheduler                string `json:"scheduler,omitempty"`
+	Controller               string `json:"controller,omitempty"`
+	ResourceTopologyExporter string `json:"resourceTopologyExporter,omitempty"`
+	NodeFeatureDiscovery     string `json:"nodeFeatureDiscovery,omitempty"`
+	Pause                    string `json:"pause,omitempty"`
+
+	sources map[string]Source
+	// origins are the references of the images before their digests were pinned
+	origins map[string]string
+}
+
+// Keys returns the image keys, in a stable order.
+func Keys() []string {
+	return []string{KeyScheduler, KeyController, KeyResourceTopologyExporter, KeyNodeFeatureDiscovery, KeyPause}
+}
+
+// Defaults returns the images this deployer was tested with.
+func Defaults(useSHA bool) ImageSet {
+	is := ImageSet{
+		Scheduler:                SchedulerPluginSchedulerDefaultImageTag,
+		Controller:               SchedulerPluginControllerDefaultImageTag,
+		ResourceTopologyExporter: ResourceTopologyExporterDefaultImageTag,
+		NodeFeatureDiscovery:     NodeFeatureDiscoveryDefaultImageTag,
+		Pause:                    PauseDefaultImage,
+	}
 	if useSHA {
-		SchedulerPluginSchedulerImage = SchedulerPluginSchedulerDefaultImageSHA
-		SchedulerPluginControllerImage = SchedulerPluginControllerDefaultImageSHA
-		ResourceTopologyExporterImage = ResourceTopologyExporterDefaultImageSHA
-		NodeFeatureDiscoveryImage = NodeFeatureDiscoveryDefaultImageSHA
-	} else {
-		SchedulerPluginSchedulerImage = SchedulerPluginSchedulerDefaultImageTag
-		SchedulerPluginControllerImage = SchedulerPluginControllerDefaultImageTag
-		ResourceTopologyExporterImage = ResourceTopologyExporterDefaultImageTag
-		NodeFeatureDiscoveryImage = NodeFeatureDiscoveryDefaultImageTag
+		is.Scheduler = SchedulerPluginSchedulerDefaultImageSHA
+		is.Controller = SchedulerPluginControllerDefaultImageSHA
+		is.ResourceTopologyExporter = ResourceTopologyExporterDefaultImageSHA
+		is.NodeFeatureDiscovery = NodeFeatureDiscoveryDefaultImageSHA
+	}
+	return is.withSource(SourceDefault)
+}
+
+// FromEnv reads the images from the environment variables listed in EnvVars.
+func FromEnv(getenv func(string) (string, bool)) ImageSet {
+	var is ImageSet
+	for _, key := range Keys() {
+		if image, ok := getenv(EnvVars[key]); ok && image != "" {
+			is.Set(key, image, SourceEnv)
+		}
 	}
+	return is
 }
 
-func Setup(getImage func(string) (string, bool)) {
-	if schedImage, ok := getImage("TAS_SCHEDULER_PLUGIN_IMAGE"); ok {
-		SchedulerPluginSchedulerImage = schedImage
+// FromFile reads the images from a YAML or JSON file, whose keys are the image keys.
+func FromFile(path string) (ImageSet, error) {
+	var is ImageSet
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return is, err
 	}
-	if schedCtrlImage, ok := getImage("TAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE"); ok {
-		SchedulerPluginControllerImage = schedCtrlImage
+	if err := yaml.UnmarshalStrict(data, &is); err != nil {
+		return is, fmt.Errorf("invalid image set file %q: %w", path, err)
 	}
-	if rteImage, ok := getImage("TAS_RESOURCE_EXPORTER_IMAGE"); ok {
-		ResourceTopologyExporterImage = rteImage
+	return is.withSource(SourceFile), nil
+}
+
+// Set changes the image of the given key, which must be one of Keys.
+func (is *ImageSet) Set(key, image string, src Source) error {
+	field := is.field(key)
+	if field == nil {
+		return fmt.Errorf("unknown image %q, expected one of %s", key, strings.Join(Keys(), ", "))
 	}
-	if nfdImage, ok := getImage("TAS_NODE_FEATURE_DISCOVERY_IMAGE"); ok {
-		NodeFeatureDiscoveryImage = nfdImage
+	*field = image
+	if is.sources == nil {
+		is.sources = make(map[string]Source)
 	}
+	is.sources[key] = src
+	delete(is.origins, key)
+	return nil
 }
 
-var (
-	SchedulerPluginSchedulerImage  = SchedulerPluginSchedulerDefaultImageTag
-	SchedulerPluginControllerImage = SchedulerPluginControllerDefaultImageTag
-	ResourceTopologyExporterImage  = ResourceTopologyExporterDefaultImageTag
-	NodeFeatureDiscoveryImage      = NodeFeatureDiscoveryDefaultImageTag
-)
+// Pin replaces the tag of the image of the given key with the digest, recording the original reference.
+// Pinning an image again to the same digest keeps the original reference.
+func (is *ImageSet) Pin(key, digest string) error {
+	image, src := is.Get(key)
+	if image == "" {
+		return fmt.Errorf("cannot pin unset image %q", key)
+	}
+	if image == Repository(image)+"@"+digest {
+		return nil
+	}
+	if err := is.Set(key, Repository(image)+"@"+digest, src); err != nil {
+		return err
+	}
+	if is.origins == nil {
+		is.origins = make(map[string]string)
+	}
+	is.origins[key] = image
+	return nil
+}
+
+// Origins maps the pinned images to their original references.
+func (is ImageSet) Origins() map[string]string {
+	ret := make(map[string]string, len(is.origins))
+	for key, origin := range is.origins {
+		if image, _ := is.Get(key); image != "" {
+			ret[image] = origin
+		}
+	}
+	return ret
+}
+
+// Get returns the image of the given key, and where it comes from.
+func (is ImageSet) Get(key string) (string, Source) {
+	field := is.field(key)
+	if field == nil || *field == "" {
+		return "", ""
+	}
+	return *field, is.sources[key]
+}
+
+// Merge returns a copy of the set with the images set in other taking precedence.
+func (is ImageSet) Merge(other ImageSet) ImageSet {
+	ret := is.clone()
+	for _, key := range Keys() {
+		if image, src := other.Get(key); image != "" {
+			ret.Set(key, image, src)
+			if origin, ok := other.origins[key]; ok {
+				ret.origins[key] = origin
+			}
+		}
+	}
+	return ret
+}
+
+// WithDefaults fills the unset images with the tag defaults.
+func (is ImageSet) WithDefaults() ImageSet {
+	return Defaults(false).Merge(is)
+}
+
+// WithRegistryMirror makes all the images pulled from the given mirror, see MirrorImage.
+// Sources are unchanged.
+func (is ImageSet) WithRegistryMirror(mirror string) ImageSet {
+	ret := is.clone()
+	if mirror == "" {
+		return ret
+	}
+	for _, key := range Keys() {
+		field := ret.field(key)
+		if *field != "" {
+			*field = MirrorImage(*field, mirror)
+		}
+	}
+	return ret
+}
+
+func (is *ImageSet) field(key string) *string {
+	switch key {
+	case KeyScheduler:
+		return &is.Scheduler
+	case KeyController:
+		return &is.Controller
+	case KeyResourceTopologyExporter:
+		return &is.ResourceTopologyExporter
+	case KeyNodeFeatureDiscovery:
+		return &is.NodeFeatureDiscovery
+	case KeyPause:
+		return &is.Pause
+	default:
+		return nil
+	}
+}
+
+func (is ImageSet) clone() ImageSet {
+	ret := is
+	ret.sources = make(map[string]Source, len(is.sources))
+	for key, src := range is.sources {
+		ret.sources[key] = src
+	}
+	ret.origins = make(map[string]string, len(is.origins))
+	for key, origin := range is.origins {
+		ret.origins[key] = origin
+	}
+	return ret
+}
+
+func (is ImageSet) withSource(src Source) ImageSet {
+	ret := is.clone()
+	for _, key := range Keys() {
+		if *ret.field(key) != "" {
+			ret.sources[key] = src
+		}
+	}
+	return ret
+}
+
+// MirrorImage replaces the registry of the image with the mirror, which can include
+// a path, keeping the repository: mirror.lan/tas + registry.k8s.io/nfd/nfd:v1 = mirror.lan/tas/nfd/nfd:v1
+func MirrorImage(image, mirror string) string {
+	_, repo := SplitRegistry(image)
+	return strings.TrimSuffix(mirror, "/") + "/" + repo
+}
+
+// SplitRegistry returns the registry host of the image, empty if the image doesn't name one,
+// and the rest of the image: registry.k8s.io/pause:3.9 = registry.k8s.io + pause:3.9
+func SplitRegistry(image string) (string, string) {
+	if host, rest, ok := strings.Cut(image, "/"); ok && isRegistryHost(host) {
+		return host, rest
+	}
+	return "", image
+}
+
+// SplitImage returns the registry, the repository and the reference (tag or digest) of the image.
+// The registry is empty if the image doesn't name one, the reference defaults to the latest tag.
+func SplitImage(image string) (string, string, string) {
+	host, rest := SplitRegistry(image)
+	if repo, digest, ok := strings.Cut(rest, "@"); ok {
+		return host, repo, digest
+	}
+	if idx := strings.LastIndex(rest, ":"); idx > strings.LastIndex(rest, "/") {
+		return host, rest[:idx], rest[idx+1:]
+	}
+	return host, rest, "latest"
+}
+
+// Repository returns the image without its tag or digest.
+func Repository(image string) string {
+	host, repo, _ := SplitImage(image)
+	if host == "" {
+		return repo
+	}
+	return host + "/" + repo
+}
+
+// isRegistryHost follows the docker reference rules: the first component is
+// a registry only if it looks like a hostname
+func isRegistryHost(host string) bool {
+	return strings.ContainsAny(host, ".:") || host == "localhost"
+}
@@ -0,0 +1,212 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMirrorImage(t *testing.T) {
+	type testCase struct {
+		image    string
+		mirror   string
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			image:    "registry.k8s.io/nfd/node-feature-discovery:v0.14.0",
+			mirror:   "mirror.lan",
+			expected: "mirror.lan/nfd/node-feature-discovery:v0.14.0",
+		},
+		{
+			image:    "quay.io/k8stopologyawareschedwg/resource-topology-exporter@sha256:577e68f5a8956a55a5cc0e2bc7183cd13d79f95c0a8feaeca98535b4de1e9116",
+			mirror:   "mirror.lan:5000/tas/",
+			expected: "mirror.lan:5000/tas/k8stopologyawareschedwg/resource-topology-exporter@sha256:577e68f5a8956a55a5cc0e2bc7183cd13d79f95c0a8feaeca98535b4de1e9116",
+		},
+		{
+			image:    "localhost/pause:3.0",
+			mirror:   "mirror.lan",
+			expected: "mirror.lan/pause:3.0",
+		},
+		{
+			image:    "library/pause:3.0",
+			mirror:   "mirror.lan",
+			expected: "mirror.lan/library/pause:3.0",
+		},
+		{
+			image:    "pause:3.0",
+			mirror:   "mirror.lan",
+			expected: "mirror.lan/pause:3.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			got := MirrorImage(tc.image, tc.mirror)
+			if got != tc.expected {
+				t.Errorf("got %q expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestImageSetMerge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "images.yaml")
+	err := os.WriteFile(path, []byte("scheduler: file/sched:v1\npause: file/pause:v1\n"), 0644)
+	if err != nil {
+		t.Fatalf("cannot write the image set file: %v", err)
+	}
+	fileImgs, err := FromFile(path)
+	if err != nil {
+		t.Fatalf("cannot read the image set file: %v", err)
+	}
+
+	is := Defaults(false).Merge(FromEnv(testGetImage)).Merge(fileImgs)
+	if err := is.Set(KeyPause, "flag/pause:v2", SourceFlag); err != nil {
+		t.Fatalf("cannot set the pause image: %v", err)
+	}
+
+	type testCase struct {
+		key            string
+		expectedImage  string
+		expectedSource Source
+	}
+
+	testCases := []testCase{
+		{key: KeyScheduler, expectedImage: "file/sched:v1", expectedSource: SourceFile},
+		{key: KeyController, expectedImage: "sched_ctrl", expectedSource: SourceEnv},
+		{key: KeyResourceTopologyExporter, expectedImage: "rte", expectedSource: SourceEnv},
+		{key: KeyPause, expectedImage: "flag/pause:v2", expectedSource: SourceFlag},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			image, src := is.Get(tc.key)
+			if image != tc.expectedImage || src != tc.expectedSource {
+				t.Errorf("got %q from %q expected %q from %q", image, src, tc.expectedImage, tc.expectedSource)
+			}
+		})
+	}
+
+	// merging must not alter the inputs
+	if image, _ := fileImgs.Get(KeyPause); image != "file/pause:v1" {
+		t.Errorf("file image set modified: pause=%q", image)
+	}
+}
+
+func TestImageSetErrors(t *testing.T) {
+	var is ImageSet
+	if err := is.Set("foobar", "foo/bar:v1", SourceFlag); err == nil {
+		t.Errorf("unknown key accepted")
+	}
+
+	path := filepath.Join(t.TempDir(), "images.yaml")
+	err := os.WriteFile(path, []byte("foobar: foo/bar:v1\n"), 0644)
+	if err != nil {
+		t.Fatalf("cannot write the image set file: %v", err)
+	}
+	if _, err := FromFile(path); err == nil {
+		t.Errorf("unknown key accepted in the image set file")
+	}
+}
+
+func TestImageSetWithRegistryMirror(t *testing.T) {
+	is := ImageSet{}
+	is.Set(KeyPause, "registry.k8s.io/pause:3.9", SourceFlag)
+	got := is.WithRegistryMirror("mirror.lan").WithDefaults()
+	if image, src := got.Get(KeyPause); image != "mirror.lan/pause:3.9" || src != SourceFlag {
+		t.Errorf("unexpected pause image %q from %q", image, src)
+	}
+	if image, _ := got.Get(KeyScheduler); image != SchedulerPluginSchedulerDefaultImageTag {
+		t.Errorf("unset image mirrored: %q", image)
+	}
+	if image, _ := is.Get(KeyPause); image != "registry.k8s.io/pause:3.9" {
+		t.Errorf("original image set modified: %q", image)
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	type testCase struct {
+		image        string
+		expectedHost string
+		expectedRepo string
+		expectedRef  string
+	}
+
+	testCases := []testCase{
+		{
+			image:        "registry.k8s.io/pause:3.9",
+			expectedHost: "registry.k8s.io",
+			expectedRepo: "pause",
+			expectedRef:  "3.9",
+		},
+		{
+			image:        "localhost:5000/tas/nfd/node-feature-discovery@sha256:0123",
+			expectedHost: "localhost:5000",
+			expectedRepo: "tas/nfd/node-feature-discovery",
+			expectedRef:  "sha256:0123",
+		},
+		{
+			image:        "library/pause",
+			expectedRepo: "library/pause",
+			expectedRef:  "latest",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			host, repo, ref := SplitImage(tc.image)
+			if host != tc.expectedHost || repo != tc.expectedRepo || ref != tc.expectedRef {
+				t.Errorf("got %q %q %q expected %q %q %q", host, repo, ref, tc.expectedHost, tc.expectedRepo, tc.expectedRef)
+			}
+		})
+	}
+}
+
+func TestImageSetPin(t *testing.T) {
+	digest := "sha256:577e68f5a8956a55a5cc0e2bc7183cd13d79f95c0a8feaeca98535b4de1e9116"
+	var is ImageSet
+	is.Set(KeyPause, "registry.k8s.io/pause:3.9", SourceDefault)
+	if err := is.Pin(KeyPause, digest); err != nil {
+		t.Fatalf("cannot pin the pause image: %v", err)
+	}
+	pinned := "registry.k8s.io/pause@" + digest
+	if image, src := is.Get(KeyPause); image != pinned || src != SourceDefault {
+		t.Errorf("got %q from %q expected %q", image, src, pinned)
+	}
+
+	// pinning again to the same digest keeps the original reference
+	if err := is.Pin(KeyPause, digest); err != nil {
+		t.Fatalf("cannot pin again the pause image: %v", err)
+	}
+
+	merged := ImageSet{}.Merge(is).WithDefaults()
+	expected := map[string]string{pinned: "registry.k8s.io/pause:3.9"}
+	if origins := merged.Origins(); len(origins) != 1 || origins[pinned] != expected[pinned] {
+		t.Errorf("got origins %v expected %v", origins, expected)
+	}
+
+	// overriding a pinned image drops its origin
+	merged.Set(KeyPause, "foo/pause:v1", SourceFlag)
+	if origins := merged.Origins(); len(origins) != 0 {
+		t.Errorf("unexpected origins %v", origins)
+	}
+}
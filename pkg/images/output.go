@@ -28,14 +28,20 @@ type Output struct {
 	TopologyUpdater     string `json:"topology_updater"`
 	SchedulerPlugin     string `json:"scheduler_plugin"`
 	SchedulerController string `json:"scheduler_controller"`
+	Pause               string `json:"pause"`
+	// Sources are keyed by the JSON names of the fields above
+	Sources map[string]Source `json:"sources"`
 }
 
-func NewOutput(updaterImage string) Output {
+// NewOutput reports the images of the set; updaterKey selects the topology updater image.
+func NewOutput(is ImageSet, updaterKey string) Output {
 	imo := Output{
-		SchedulerPlugin:     SchedulerPluginSchedulerImage,
-		SchedulerController: SchedulerPluginControllerImage,
-		TopologyUpdater:     updaterImage,
+		Sources: make(map[string]Source),
 	}
+	imo.SchedulerPlugin, imo.Sources["scheduler_plugin"] = is.Get(KeyScheduler)
+	imo.SchedulerController, imo.Sources["scheduler_controller"] = is.Get(KeyController)
+	imo.TopologyUpdater, imo.Sources["topology_updater"] = is.Get(updaterKey)
+	imo.Pause, imo.Sources["pause"] = is.Get(KeyPause)
 	return imo
 }
 
@@ -46,6 +52,7 @@ func (imo Output) ToList() List {
 		imo.TopologyUpdater,
 		imo.SchedulerPlugin,
 		imo.SchedulerController,
+		imo.Pause,
 	}
 }
 
@@ -74,10 +81,13 @@ func (il List) Format(kind int, w io.Writer) {
 	}
 }
 
+// EncodeText emits the images as environment variables, so the output can be sourced.
+// The sources are emitted as comments.
 func (imo Output) EncodeText(w io.Writer) {
-	fmt.Fprintf(w, "TAS_SCHEDULER_PLUGIN_IMAGE=%s\n", imo.SchedulerPlugin)
-	fmt.Fprintf(w, "TAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE=%s\n", imo.SchedulerController)
-	fmt.Fprintf(w, "TAS_RESOURCE_EXPORTER_IMAGE=%s\n", imo.TopologyUpdater)
+	fmt.Fprintf(w, "# source: %s\nTAS_SCHEDULER_PLUGIN_IMAGE=%s\n", imo.Sources["scheduler_plugin"], imo.SchedulerPlugin)
+	fmt.Fprintf(w, "# source: %s\nTAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE=%s\n", imo.Sources["scheduler_controller"], imo.SchedulerController)
+	fmt.Fprintf(w, "# source: %s\nTAS_RESOURCE_EXPORTER_IMAGE=%s\n", imo.Sources["topology_updater"], imo.TopologyUpdater)
+	fmt.Fprintf(w, "# source: %s\nTAS_PAUSE_IMAGE=%s\n", imo.Sources["pause"], imo.Pause)
 }
 
 func (imo Output) EncodeJSON(w io.Writer) {
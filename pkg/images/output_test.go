@@ -23,55 +23,51 @@ import (
 )
 
 func TestOutputBasics(t *testing.T) {
-	Setup(testGetImage)
-
-	imo := NewOutput("foobar")
+	imo := NewOutput(FromEnv(testGetImage).WithDefaults(), KeyResourceTopologyExporter)
 	images := imo.ToList()
-	if len(images) != 3 {
+	if len(images) != 4 {
 		t.Errorf("unexpected image list content: %#v", images)
 	}
 }
 
 func TestOutput(t *testing.T) {
 	type testCase struct {
-		name         string
-		kind         int
-		updaterImage string
-		expected     string
+		name       string
+		kind       int
+		updaterKey string
+		expected   string
 	}
 
 	testCases := []testCase{
 		{
-			name:         "text/rte",
-			kind:         FormatText,
-			updaterImage: ResourceTopologyExporterImage,
-			expected:     "TAS_SCHEDULER_PLUGIN_IMAGE=sched_sched\nTAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE=sched_ctrl\nTAS_RESOURCE_EXPORTER_IMAGE=rte",
+			name:       "text/rte",
+			kind:       FormatText,
+			updaterKey: KeyResourceTopologyExporter,
+			expected:   "# source: env\nTAS_SCHEDULER_PLUGIN_IMAGE=sched_sched\n# source: env\nTAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE=sched_ctrl\n# source: env\nTAS_RESOURCE_EXPORTER_IMAGE=rte\n# source: default\nTAS_PAUSE_IMAGE=" + PauseDefaultImage,
 		},
 		{
-			name:         "text/nfd",
-			kind:         FormatText,
-			updaterImage: NodeFeatureDiscoveryImage,
-			expected:     "TAS_SCHEDULER_PLUGIN_IMAGE=sched_sched\nTAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE=sched_ctrl\nTAS_RESOURCE_EXPORTER_IMAGE=nfd",
+			name:       "text/nfd",
+			kind:       FormatText,
+			updaterKey: KeyNodeFeatureDiscovery,
+			expected:   "# source: env\nTAS_SCHEDULER_PLUGIN_IMAGE=sched_sched\n# source: env\nTAS_SCHEDULER_PLUGIN_CONTROLLER_IMAGE=sched_ctrl\n# source: env\nTAS_RESOURCE_EXPORTER_IMAGE=nfd\n# source: default\nTAS_PAUSE_IMAGE=" + PauseDefaultImage,
 		},
 		{
-			name:         "json/rte",
-			kind:         FormatJSON,
-			updaterImage: ResourceTopologyExporterImage,
-			expected:     `{"topology_updater":"rte","scheduler_plugin":"sched_sched","scheduler_controller":"sched_ctrl"}`,
+			name:       "json/rte",
+			kind:       FormatJSON,
+			updaterKey: KeyResourceTopologyExporter,
+			expected:   `{"topology_updater":"rte","scheduler_plugin":"sched_sched","scheduler_controller":"sched_ctrl","pause":"` + PauseDefaultImage + `","sources":{"pause":"default","scheduler_controller":"env","scheduler_plugin":"env","topology_updater":"env"}}`,
 		},
 		{
-			name:         "json/nfd",
-			kind:         FormatJSON,
-			updaterImage: NodeFeatureDiscoveryImage,
-			expected:     `{"topology_updater":"nfd","scheduler_plugin":"sched_sched","scheduler_controller":"sched_ctrl"}`,
+			name:       "json/nfd",
+			kind:       FormatJSON,
+			updaterKey: KeyNodeFeatureDiscovery,
+			expected:   `{"topology_updater":"nfd","scheduler_plugin":"sched_sched","scheduler_controller":"sched_ctrl","pause":"` + PauseDefaultImage + `","sources":{"pause":"default","scheduler_controller":"env","scheduler_plugin":"env","topology_updater":"env"}}`,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			Setup(testGetImage)
-
-			imo := NewOutput(tc.updaterImage)
+			imo := NewOutput(FromEnv(testGetImage).WithDefaults(), tc.updaterKey)
 			var buf bytes.Buffer
 			imo.Format(tc.kind, &buf)
 			got := strings.TrimSpace(buf.String())
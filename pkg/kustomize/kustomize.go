@@ -0,0 +1,75 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package kustomize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+const (
+	KustomizationFileName   = "kustomization.yaml"
+	KustomizationAPIVersion = "kustomize.config.k8s.io/v1beta1"
+	KustomizationKind       = "Kustomization"
+)
+
+// Kustomization is the minimal subset of the kustomize configuration we need to generate
+type Kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+}
+
+func NewKustomization(resources []string) Kustomization {
+	return Kustomization{
+		APIVersion: KustomizationAPIVersion,
+		Kind:       KustomizationKind,
+		Resources:  resources,
+	}
+}
+
+// WriteBase writes the components as a kustomize base rooted in baseDir:
+// one directory per component, holding one file per object and its own kustomization,
+// and a top-level kustomization referencing all the component directories.
+func WriteBase(baseDir string, comps []manifests.Component) error {
+	var compDirs []string
+	for _, comp := range comps {
+		compDir := filepath.Join(baseDir, comp.Name)
+		fileNames, err := manifests.WriteObjects(compDir, comp.Objects)
+		if err != nil {
+			return fmt.Errorf("cannot write component %q: %w", comp.Name, err)
+		}
+		if err := writeKustomization(compDir, fileNames); err != nil {
+			return fmt.Errorf("cannot write component %q: %w", comp.Name, err)
+		}
+		compDirs = append(compDirs, comp.Name)
+	}
+	return writeKustomization(baseDir, compDirs)
+}
+
+func writeKustomization(dir string, resources []string) error {
+	data, err := yaml.Marshal(NewKustomization(resources))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, KustomizationFileName), data, 0644)
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+func TestWriteBase(t *testing.T) {
+	baseDir := t.TempDir()
+	comps := []manifests.Component{
+		{
+			Name: "api",
+			Objects: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+			},
+		},
+		{
+			Name: "topology-updater",
+			Objects: []client.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte"}},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte-config"}},
+			},
+		},
+	}
+
+	if err := WriteBase(baseDir, comps); err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+
+	expected := map[string][]string{
+		"":                 {"api", "topology-updater"},
+		"api":              {"namespace-foo.yaml"},
+		"topology-updater": {"serviceaccount-rte.yaml", "configmap-rte-config.yaml"},
+	}
+	for dir, resources := range expected {
+		data, err := os.ReadFile(filepath.Join(baseDir, dir, KustomizationFileName))
+		if err != nil {
+			t.Fatalf("cannot read the kustomization in %q: %v", dir, err)
+		}
+		var kst Kustomization
+		if err := yaml.Unmarshal(data, &kst); err != nil {
+			t.Fatalf("cannot decode the kustomization in %q: %v", dir, err)
+		}
+		if !reflect.DeepEqual(kst, NewKustomization(resources)) {
+			t.Errorf("kustomization mismatch in %q: got %+v expected resources %v", dir, kst, resources)
+		}
+		if dir == "" {
+			continue
+		}
+		for _, res := range resources {
+			if _, err := os.Stat(filepath.Join(baseDir, dir, res)); err != nil {
+				t.Errorf("missing resource %q in %q: %v", res, dir, err)
+			}
+		}
+	}
+}
@@ -17,40 +17,74 @@
 package api
 
 import (
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 )
 
 type Manifests struct {
 	Crd *apiextensionv1.CustomResourceDefinition
+	// PriorityClass is optional, shared by all the components
+	PriorityClass *schedulingv1.PriorityClass
 	// internal fields
 	plat platform.Platform
 }
 
 func (mf Manifests) ToObjects() []client.Object {
-	return []client.Object{
+	objs := []client.Object{
 		mf.Crd,
 	}
+	if mf.PriorityClass != nil {
+		objs = append(objs, mf.PriorityClass)
+	}
+	return objs
 }
 
 func (mf Manifests) Clone() Manifests {
 	return Manifests{
 		plat: mf.plat,
 		// objects
-		Crd: mf.Crd.DeepCopy(),
+		Crd:           mf.Crd.DeepCopy(),
+		PriorityClass: mf.PriorityClass.DeepCopy(),
 	}
 }
 
-func (mf Manifests) Render() (Manifests, error) {
+type RenderOptions struct {
+	Metadata objectupdate.MetadataOptions
+	// PriorityClassName, if not empty, makes Render add a PriorityClass with the given value
+	PriorityClassName  string
+	PriorityClassValue int32
+}
+
+func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
 	ret := mf.Clone()
-	// nothing to do atm
+	if options.PriorityClassName != "" {
+		ret.PriorityClass = CreatePriorityClass(options.PriorityClassName, options.PriorityClassValue)
+	}
+	objectupdate.Metadata(ret.ToObjects(), options.Metadata)
 	return ret, nil
 }
 
+func CreatePriorityClass(name string, value int32) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PriorityClass",
+			APIVersion: "scheduling.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Value:       value,
+		Description: "priority of the topology-aware-scheduling components",
+	}
+}
+
 func New(plat platform.Platform) Manifests {
 	return Manifests{
 		plat: plat,
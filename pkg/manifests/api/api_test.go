@@ -80,7 +80,7 @@ func TestRender(t *testing.T) {
 			t.Fatalf("GetManifests() failed: %v", err)
 		}
 		mfBeforeRender := tc.mf.Clone()
-		uMf, err := tc.mf.Render()
+		uMf, err := tc.mf.Render(RenderOptions{})
 		if err != nil {
 			t.Errorf("testcase %q, Render() failed: %v", tc.name, err)
 		}
@@ -124,3 +124,29 @@ func TestToObjects(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderPriorityClass(t *testing.T) {
+	mf, err := GetManifests(platform.Kubernetes)
+	if err != nil {
+		t.Fatalf("GetManifests() failed: %v", err)
+	}
+
+	uMf, err := mf.Render(RenderOptions{})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if uMf.PriorityClass != nil || len(uMf.ToObjects()) != 1 {
+		t.Errorf("unexpected PriorityClass rendered by default")
+	}
+
+	uMf, err = mf.Render(RenderOptions{PriorityClassName: "prio", PriorityClassValue: 42})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if uMf.PriorityClass == nil || uMf.PriorityClass.Name != "prio" || uMf.PriorityClass.Value != 42 {
+		t.Fatalf("unexpected PriorityClass: %v", uMf.PriorityClass)
+	}
+	if len(uMf.ToObjects()) != 2 {
+		t.Errorf("PriorityClass missing from the objects")
+	}
+}
@@ -31,14 +31,31 @@ import (
 )
 
 func SerializeObject(obj runtime.Object, out io.Writer) error {
-	jsonBytes, err := json.Marshal(obj)
+	r, err := toCleanUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	srz := k8sjson.NewYAMLSerializer(k8sjson.DefaultMetaFactory, k8sscheme.Scheme, k8sscheme.Scheme)
+	return srz.Encode(r, out)
+}
+
+func SerializeObjectJSON(obj runtime.Object, out io.Writer) error {
+	r, err := toCleanUnstructured(obj)
 	if err != nil {
 		return err
 	}
+	return newJSONSerializer().Encode(r, out)
+}
+
+func toCleanUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
 
 	var r unstructured.Unstructured
 	if err := json.Unmarshal(jsonBytes, &r.Object); err != nil {
-		return err
+		return nil, err
 	}
 
 	// remove status and metadata.creationTimestamp
@@ -46,9 +63,11 @@ func SerializeObject(obj runtime.Object, out io.Writer) error {
 	unstructured.RemoveNestedField(r.Object, "template", "metadata", "creationTimestamp")
 	unstructured.RemoveNestedField(r.Object, "spec", "template", "metadata", "creationTimestamp")
 	unstructured.RemoveNestedField(r.Object, "status")
+	return &r, nil
+}
 
-	srz := k8sjson.NewYAMLSerializer(k8sjson.DefaultMetaFactory, k8sscheme.Scheme, k8sscheme.Scheme)
-	return srz.Encode(&r, out)
+func newJSONSerializer() *k8sjson.Serializer {
+	return k8sjson.NewSerializerWithOptions(k8sjson.DefaultMetaFactory, k8sscheme.Scheme, k8sscheme.Scheme, k8sjson.SerializerOptions{Pretty: true})
 }
 
 func SerializeObjectToData(obj runtime.Object) ([]byte, error) {
@@ -84,3 +103,34 @@ func RenderObjects(objs []client.Object, w io.Writer) error {
 
 	return nil
 }
+
+// RenderObjectsJSON emits the objects as a stream of JSON documents, which kubectl can consume.
+func RenderObjectsJSON(objs []client.Object, w io.Writer) error {
+	for _, obj := range objs {
+		if err := SerializeObjectJSON(obj, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderObjectsList emits the objects wrapped in a single v1/List object, as YAML.
+func RenderObjectsList(objs []client.Object, w io.Writer) error {
+	items := make([]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		r, err := toCleanUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		items = append(items, r.Object)
+	}
+	list := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		},
+	}
+	srz := k8sjson.NewYAMLSerializer(k8sjson.DefaultMetaFactory, k8sscheme.Scheme, k8sscheme.Scheme)
+	return srz.Encode(&list, w)
+}
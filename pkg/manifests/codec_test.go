@@ -0,0 +1,90 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRenderObjectsJSON(t *testing.T) {
+	objs := testObjects()
+	var buf bytes.Buffer
+	if err := RenderObjectsJSON(objs, &buf); err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+
+	dec := json.NewDecoder(&buf)
+	var names []string
+	for {
+		var obj map[string]interface{}
+		err := dec.Decode(&obj)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("cannot decode the JSON stream: %v", err)
+		}
+		if _, ok := obj["status"]; ok {
+			t.Errorf("unexpected status in %v", obj)
+		}
+		names = append(names, obj["metadata"].(map[string]interface{})["name"].(string))
+	}
+	if len(names) != len(objs) || names[0] != "foo" || names[1] != "bar" {
+		t.Errorf("unexpected objects: %v", names)
+	}
+}
+
+func TestRenderObjectsList(t *testing.T) {
+	objs := testObjects()
+	var buf bytes.Buffer
+	if err := RenderObjectsList(objs, &buf); err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+
+	var list corev1.List
+	if err := yaml.Unmarshal(buf.Bytes(), &list); err != nil {
+		t.Fatalf("cannot decode the list: %v", err)
+	}
+	if list.APIVersion != "v1" || list.Kind != "List" {
+		t.Errorf("unexpected type: %v", list.TypeMeta)
+	}
+	if len(list.Items) != len(objs) {
+		t.Errorf("unexpected items: %d expected %d", len(list.Items), len(objs))
+	}
+}
+
+func testObjects() []client.Object {
+	return []client.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+		},
+		&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "bar"},
+		},
+	}
+}
@@ -30,8 +30,10 @@ import (
 	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
@@ -51,12 +53,16 @@ const (
 const (
 	SubComponentSchedulerPluginScheduler            = "scheduler"
 	SubComponentSchedulerPluginController           = "controller"
+	SubComponentSchedulerPluginExtraProfile         = "extraprofile"
 	SubComponentNodeFeatureDiscoveryTopologyUpdater = "topologyupdater"
 )
 
 const (
 	ContainerNameRTE                = "resource-topology-exporter"
+	ContainerNameRTESharedPool      = "shared-pool-container"
 	ContainerNameNFDTopologyUpdater = "nfd-topology-updater"
+	ContainerNameScheduler          = "topology-aware-scheduler"
+	ContainerNameController         = "topology-aware-controller"
 )
 
 const (
@@ -275,6 +281,29 @@ func Deployment(component, subComponent, namespace string) (*appsv1.Deployment,
 	return dp, nil
 }
 
+func PodDisruptionBudget(component, subComponent, namespace string) (*policyv1.PodDisruptionBudget, error) {
+	if err := validateComponent(component); err != nil {
+		return nil, err
+	}
+	if err := validateSubComponent(component, subComponent); err != nil {
+		return nil, err
+	}
+	obj, err := loadObject(filepath.Join("yaml", component, subComponent, "poddisruptionbudget.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	pdb, ok := obj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type, got %t", obj)
+	}
+
+	if namespace != "" {
+		pdb.Namespace = namespace
+	}
+	return pdb, nil
+}
+
 func DaemonSet(component, subComponent string, namespace string) (*appsv1.DaemonSet, error) {
 	if err := validateComponent(component); err != nil {
 		return nil, err
@@ -455,7 +484,7 @@ func validateSubComponent(component, subComponent string) error {
 	if subComponent == "" {
 		return nil
 	}
-	if component == ComponentSchedulerPlugin && (subComponent == SubComponentSchedulerPluginController || subComponent == SubComponentSchedulerPluginScheduler) {
+	if component == ComponentSchedulerPlugin && (subComponent == SubComponentSchedulerPluginController || subComponent == SubComponentSchedulerPluginScheduler || subComponent == SubComponentSchedulerPluginExtraProfile) {
 		return nil
 	}
 	if component == ComponentNodeFeatureDiscovery && (subComponent == SubComponentNodeFeatureDiscoveryTopologyUpdater) {
@@ -487,3 +516,21 @@ func Service(component, subComponent, namespace string) (*corev1.Service, error)
 	}
 	return sv, nil
 }
+
+// CreatePullSecret makes a Secret holding the content of a docker config file, to be used as imagePullSecret.
+func CreatePullSecret(namespace, name string, dockerConfigData []byte) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerConfigData,
+		},
+	}
+}
@@ -35,6 +35,8 @@ type Manifests struct {
 	CRTopologyUpdater  *rbacv1.ClusterRole
 	CRBTopologyUpdater *rbacv1.ClusterRoleBinding
 	DSTopologyUpdater  *appsv1.DaemonSet
+	// PullSecret is optional
+	PullSecret *corev1.Secret
 
 	plat platform.Platform
 }
@@ -47,6 +49,7 @@ func (mf Manifests) Clone() Manifests {
 		CRBTopologyUpdater: mf.CRBTopologyUpdater.DeepCopy(),
 		DSTopologyUpdater:  mf.DSTopologyUpdater.DeepCopy(),
 		SATopologyUpdater:  mf.SATopologyUpdater.DeepCopy(),
+		PullSecret:         mf.PullSecret.DeepCopy(),
 	}
 
 	return ret
@@ -60,6 +63,8 @@ type RenderOptions struct {
 
 	// General options
 	Namespace string
+	Metadata  objectupdate.MetadataOptions
+	ImagePull objectupdate.ImagePullOptions
 }
 
 func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
@@ -67,6 +72,8 @@ func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
 
 	if options.Namespace != "" {
 		ret.Namespace.Name = options.Namespace
+		ret.SATopologyUpdater.Namespace = options.Namespace
+		ret.DSTopologyUpdater.Namespace = options.Namespace
 	}
 
 	rbacupdate.ClusterRoleBinding(ret.CRBTopologyUpdater, mf.SATopologyUpdater.Name, ret.Namespace.Name)
@@ -74,19 +81,33 @@ func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
 	ret.DSTopologyUpdater.Spec.Template.Spec.ServiceAccountName = mf.SATopologyUpdater.Name
 
 	nfdupdate.UpdaterDaemonSet(ret.DSTopologyUpdater, options.DaemonSet)
+	if err := objectupdate.SetPodResources(&ret.DSTopologyUpdater.Spec.Template.Spec, options.DaemonSet.Resources); err != nil {
+		return ret, err
+	}
 
+	if name := options.ImagePull.SecretToCreate(); name != "" {
+		ret.PullSecret = manifests.CreatePullSecret(ret.DSTopologyUpdater.Namespace, name, options.ImagePull.DockerConfigData)
+	}
+	objectupdate.SetImagePullSecrets(ret.ToObjects(), options.ImagePull.Secrets)
+	objectupdate.SetImageOrigins(ret.ToObjects(), options.DaemonSet.Images.Origins())
+	objectupdate.Metadata(ret.ToObjects(), options.Metadata)
 	return ret, nil
 }
 
 func (mf Manifests) ToObjects() []client.Object {
-	return []client.Object{
+	objs := []client.Object{
 		mf.Namespace,
+	}
+	if mf.PullSecret != nil {
+		objs = append(objs, mf.PullSecret)
+	}
+	return append(objs,
 		// topology-updater objects
 		mf.SATopologyUpdater,
 		mf.CRTopologyUpdater,
 		mf.CRBTopologyUpdater,
 		mf.DSTopologyUpdater,
-	}
+	)
 }
 
 func New(plat platform.Platform) Manifests {
@@ -0,0 +1,104 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package manifests
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	ResourcesPresetSmall  = "small"
+	ResourcesPresetMedium = "medium"
+	ResourcesPresetLarge  = "large"
+)
+
+type presetValues struct {
+	cpu    string
+	memory string
+}
+
+// the presets set the cpu and memory requests and the memory limits;
+// the medium preset matches the embedded scheduler manifests.
+var resourcesPresets = map[string]map[string]presetValues{
+	ResourcesPresetSmall: {
+		ContainerNameScheduler:          {cpu: "100m", memory: "256Mi"},
+		ContainerNameController:         {cpu: "50m", memory: "128Mi"},
+		ContainerNameRTE:                {cpu: "100m", memory: "128Mi"},
+		ContainerNameRTESharedPool:      {cpu: "10m", memory: "16Mi"},
+		ContainerNameNFDTopologyUpdater: {cpu: "100m", memory: "128Mi"},
+	},
+	ResourcesPresetMedium: {
+		ContainerNameScheduler:          {cpu: "200m", memory: "500Mi"},
+		ContainerNameController:         {cpu: "100m", memory: "256Mi"},
+		ContainerNameRTE:                {cpu: "200m", memory: "256Mi"},
+		ContainerNameRTESharedPool:      {cpu: "10m", memory: "16Mi"},
+		ContainerNameNFDTopologyUpdater: {cpu: "200m", memory: "256Mi"},
+	},
+	ResourcesPresetLarge: {
+		ContainerNameScheduler:          {cpu: "500m", memory: "1Gi"},
+		ContainerNameController:         {cpu: "200m", memory: "512Mi"},
+		ContainerNameRTE:                {cpu: "500m", memory: "512Mi"},
+		ContainerNameRTESharedPool:      {cpu: "10m", memory: "16Mi"},
+		ContainerNameNFDTopologyUpdater: {cpu: "500m", memory: "512Mi"},
+	},
+}
+
+// the updater containers get whole cpus when Guaranteed QoS is requested, so they can run
+// on exclusive cpus; the RTE shared pool helper stays small on purpose.
+var guaranteedUpdaterCPUs = map[string]string{
+	ResourcesPresetSmall:  "1",
+	ResourcesPresetMedium: "1",
+	ResourcesPresetLarge:  "2",
+}
+
+// ResourcesPreset returns the resources of all our containers, by container name, for the given preset.
+// With guaranteedUpdater the updater containers get whole cpus and cpu limits matching the requests.
+func ResourcesPreset(preset string, guaranteedUpdater bool) (map[string]corev1.ResourceRequirements, error) {
+	values, ok := resourcesPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown resources preset: %q", preset)
+	}
+	ret := make(map[string]corev1.ResourceRequirements, len(values))
+	for name, val := range values {
+		res := corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(val.cpu),
+				corev1.ResourceMemory: resource.MustParse(val.memory),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse(val.memory),
+			},
+		}
+		if guaranteedUpdater && isUpdaterContainer(name) {
+			cpu := val.cpu
+			if name != ContainerNameRTESharedPool {
+				cpu = guaranteedUpdaterCPUs[preset]
+			}
+			res.Requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+			res.Limits[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		ret[name] = res
+	}
+	return ret, nil
+}
+
+func isUpdaterContainer(name string) bool {
+	return name == ContainerNameRTE || name == ContainerNameRTESharedPool || name == ContainerNameNFDTopologyUpdater
+}
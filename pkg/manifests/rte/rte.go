@@ -45,6 +45,8 @@ type Manifests struct {
 	ClusterRoleBinding *rbacv1.ClusterRoleBinding
 	ConfigMap          *corev1.ConfigMap
 	DaemonSet          *appsv1.DaemonSet
+	// PullSecret is optional
+	PullSecret *corev1.Secret
 
 	// OpenShift related components
 	MachineConfig             *machineconfigv1.MachineConfig
@@ -65,6 +67,7 @@ func (mf Manifests) Clone() Manifests {
 		DaemonSet:          mf.DaemonSet.DeepCopy(),
 		ServiceAccount:     mf.ServiceAccount.DeepCopy(),
 		ConfigMap:          mf.ConfigMap.DeepCopy(),
+		PullSecret:         mf.PullSecret.DeepCopy(),
 	}
 
 	if mf.plat == platform.OpenShift {
@@ -88,13 +91,19 @@ type RenderOptions struct {
 	// General options
 	Namespace string
 	Name      string
+	Metadata  objectupdate.MetadataOptions
+	ImagePull objectupdate.ImagePullOptions
 }
 
 func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
 	ret := mf.Clone()
-	if ret.plat == platform.Kubernetes {
-		if options.Namespace != "" {
-			ret.ServiceAccount.Namespace = options.Namespace
+	if options.Namespace != "" {
+		ret.ServiceAccount.Namespace = options.Namespace
+		ret.Role.Namespace = options.Namespace
+		ret.RoleBinding.Namespace = options.Namespace
+		ret.DaemonSet.Namespace = options.Namespace
+		if ret.ConfigMap != nil {
+			ret.ConfigMap.Namespace = options.Namespace
 		}
 	}
 
@@ -107,10 +116,10 @@ func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
 		ret.ClusterRoleBinding.Name = options.Name
 	}
 
-	rbacupdate.RoleBinding(ret.RoleBinding, mf.ServiceAccount.Name, ret.ServiceAccount.Namespace)
-	rbacupdate.ClusterRoleBinding(ret.ClusterRoleBinding, mf.ServiceAccount.Name, mf.ServiceAccount.Namespace)
+	rbacupdate.RoleBinding(ret.RoleBinding, ret.ServiceAccount.Name, ret.ServiceAccount.Namespace)
+	rbacupdate.ClusterRoleBinding(ret.ClusterRoleBinding, ret.ServiceAccount.Name, ret.ServiceAccount.Namespace)
 
-	ret.DaemonSet.Spec.Template.Spec.ServiceAccountName = mf.ServiceAccount.Name
+	ret.DaemonSet.Spec.Template.Spec.ServiceAccountName = ret.ServiceAccount.Name
 
 	rteConfigMapName := ""
 	if len(options.ConfigData) > 0 {
@@ -121,6 +130,9 @@ func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
 		rteConfigMapName = ret.ConfigMap.Name
 	}
 	rteupdate.DaemonSet(ret.DaemonSet, mf.plat, rteConfigMapName, options.DaemonSet)
+	if err := objectupdate.SetPodResources(&ret.DaemonSet.Spec.Template.Spec, options.DaemonSet.Resources); err != nil {
+		return ret, err
+	}
 
 	if mf.plat == platform.OpenShift {
 		rteupdate.SecurityContext(ret.DaemonSet)
@@ -134,6 +146,12 @@ func (mf Manifests) Render(options RenderOptions) (Manifests, error) {
 		ocpupdate.SecurityContextConstraint(ret.SecurityContextConstraint, ret.ServiceAccount)
 	}
 
+	if name := options.ImagePull.SecretToCreate(); name != "" {
+		ret.PullSecret = manifests.CreatePullSecret(ret.DaemonSet.Namespace, name, options.ImagePull.DockerConfigData)
+	}
+	objectupdate.SetImagePullSecrets(ret.ToObjects(), options.ImagePull.Secrets)
+	objectupdate.SetImageOrigins(ret.ToObjects(), options.DaemonSet.Images.Origins())
+	objectupdate.Metadata(ret.ToObjects(), options.Metadata)
 	return ret, nil
 }
 
@@ -162,6 +180,10 @@ func (mf Manifests) ToObjects() []client.Object {
 		objs = append(objs, mf.ConfigMap)
 	}
 
+	if mf.PullSecret != nil {
+		objs = append(objs, mf.PullSecret)
+	}
+
 	if mf.MachineConfig != nil {
 		objs = append(objs, mf.MachineConfig)
 	}
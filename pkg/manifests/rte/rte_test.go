@@ -20,6 +20,8 @@ import (
 	"reflect"
 	"testing"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 )
 
@@ -151,3 +153,38 @@ func TestGetManifestsOpenShift(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderNamespace(t *testing.T) {
+	for _, plat := range []platform.Platform{platform.Kubernetes, platform.OpenShift} {
+		t.Run(plat.String(), func(t *testing.T) {
+			mf, err := GetManifests(plat, platform.Version("v4.11"), "", true)
+			if err != nil {
+				t.Fatalf("GetManifests() failed: %v", err)
+			}
+			uMf, err := mf.Render(RenderOptions{
+				Namespace:  "foo",
+				ConfigData: "foo: bar",
+			})
+			if err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+
+			for _, obj := range []client.Object{uMf.ServiceAccount, uMf.Role, uMf.RoleBinding, uMf.DaemonSet, uMf.ConfigMap} {
+				if obj.GetNamespace() != "foo" {
+					t.Errorf("%s namespace %q expected %q", obj.GetName(), obj.GetNamespace(), "foo")
+				}
+			}
+			for _, sub := range append(uMf.RoleBinding.Subjects, uMf.ClusterRoleBinding.Subjects...) {
+				if sub.Namespace != "foo" {
+					t.Errorf("subject %s namespace %q expected %q", sub.Name, sub.Namespace, "foo")
+				}
+			}
+			if plat == platform.OpenShift {
+				expectedUser := "system:serviceaccount:foo:" + uMf.ServiceAccount.Name
+				if !reflect.DeepEqual(uMf.SecurityContextConstraint.Users, []string{expectedUser}) {
+					t.Errorf("SCC users %v expected %q", uMf.SecurityContextConstraint.Users, expectedUser)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package sched
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+// openShiftKubeMinorOffset maps OpenShift 4.x to kubernetes 1.(x+offset)
+const openShiftKubeMinorOffset = 13
+
+// ConfigAPIVersion returns the KubeSchedulerConfiguration API version to render for the given
+// platform version and scheduler image. Returns empty if neither version can be detected.
+func ConfigAPIVersion(plat platform.Platform, ver platform.Version, schedImage string) (string, error) {
+	return manifests.SelectSchedulerConfigAPIVersion(kubeMinorFromPlatform(plat, ver), kubeMinorFromSchedulerImage(schedImage))
+}
+
+func kubeMinorFromPlatform(plat platform.Platform, ver platform.Version) int {
+	major, minor, ok := parseMajorMinor(ver.String())
+	if !ok {
+		return manifests.UnknownKubeMinor
+	}
+	if plat == platform.OpenShift && major == 4 {
+		return minor + openShiftKubeMinorOffset
+	}
+	if major != 1 {
+		return manifests.UnknownKubeMinor
+	}
+	return minor
+}
+
+// kubeMinorFromSchedulerImage detects the kubernetes minor from the image tag, either a
+// scheduler-plugins one (v0.x.y, built on kubernetes 1.x) or a kube-scheduler one (v1.x.y).
+func kubeMinorFromSchedulerImage(image string) int {
+	_, _, ref := images.SplitImage(image)
+	major, minor, ok := parseMajorMinor(ref)
+	if !ok || (major != 0 && major != 1) {
+		return manifests.UnknownKubeMinor
+	}
+	return minor
+}
+
+func parseMajorMinor(ver string) (int, int, bool) {
+	fields := strings.SplitN(strings.TrimPrefix(ver, "v"), ".", 3)
+	if len(fields) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	// tolerate suffixes like 1.26+ or 1.26-rc.0
+	minorVal := strings.TrimRightFunc(fields[1], func(r rune) bool { return r < '0' || r > '9' })
+	minor, err := strconv.Atoi(minorVal)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
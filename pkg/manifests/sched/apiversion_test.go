@@ -0,0 +1,96 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package sched
+
+import (
+	"testing"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+func TestConfigAPIVersion(t *testing.T) {
+	type testCase struct {
+		name        string
+		plat        platform.Platform
+		ver         platform.Version
+		schedImage  string
+		expected    string
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:       "nothing known",
+			plat:       platform.Kubernetes,
+			schedImage: "quay.io/foo/scheduler:latest",
+			expected:   "",
+		},
+		{
+			name:       "scheduler-plugins image only",
+			plat:       platform.Kubernetes,
+			schedImage: "registry.k8s.io/scheduler-plugins/kube-scheduler:v0.24.9",
+			expected:   manifests.SchedulerConfigAPIVersionV1beta3,
+		},
+		{
+			name:       "kube-scheduler image on an older cluster",
+			plat:       platform.Kubernetes,
+			ver:        "v1.24",
+			schedImage: "registry.k8s.io/kube-scheduler:v1.26.3",
+			expected:   manifests.SchedulerConfigAPIVersionV1beta3,
+		},
+		{
+			name:       "digest image",
+			plat:       platform.Kubernetes,
+			ver:        "1.22.4",
+			schedImage: "registry.k8s.io/scheduler-plugins/kube-scheduler@sha256:1eb894c0743d5d01e18c74150645354554201aa6e0dcae62d49a8d3657326f1a",
+			expected:   manifests.SchedulerConfigAPIVersionV1beta2,
+		},
+		{
+			name:       "openshift",
+			plat:       platform.OpenShift,
+			ver:        "4.14.2",
+			schedImage: "registry.k8s.io/scheduler-plugins/kube-scheduler:v0.27.8",
+			expected:   manifests.SchedulerConfigAPIVersionV1,
+		},
+		{
+			name:        "image too new for the cluster",
+			plat:        platform.Kubernetes,
+			ver:         "v1.22",
+			schedImage:  "registry.k8s.io/scheduler-plugins/kube-scheduler:v0.28.9",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ConfigAPIVersion(tc.plat, tc.ver, tc.schedImage)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %q expected %q", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package sched
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
+)
+
+// ExtraProfileManifests are the objects needed to run our plugin as an extra profile
+// of an existing kube-scheduler, whose image must be a scheduler-plugins build.
+type ExtraProfileManifests struct {
+	// let the existing scheduler read the NRT objects
+	CRScheduler  *rbacv1.ClusterRole
+	CRBScheduler *rbacv1.ClusterRoleBinding
+}
+
+func (mf ExtraProfileManifests) Clone() ExtraProfileManifests {
+	return ExtraProfileManifests{
+		CRScheduler:  mf.CRScheduler.DeepCopy(),
+		CRBScheduler: mf.CRBScheduler.DeepCopy(),
+	}
+}
+
+func (mf ExtraProfileManifests) ToObjects() []client.Object {
+	return []client.Object{
+		mf.CRScheduler,
+		mf.CRBScheduler,
+	}
+}
+
+func (mf ExtraProfileManifests) Render(options RenderOptions) ExtraProfileManifests {
+	ret := mf.Clone()
+	objectupdate.Metadata(ret.ToObjects(), options.Metadata)
+	return ret
+}
+
+func GetExtraProfileManifests() (ExtraProfileManifests, error) {
+	var err error
+	mf := ExtraProfileManifests{}
+	mf.CRScheduler, err = manifests.ClusterRole(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginExtraProfile)
+	if err != nil {
+		return mf, err
+	}
+	mf.CRBScheduler, err = manifests.ClusterRoleBinding(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginExtraProfile)
+	if err != nil {
+		return mf, err
+	}
+	return mf, nil
+}
+
+// RenderExtraProfileConfig returns the KubeSchedulerConfiguration holding the profiles using our
+// plugin, rendered according to the options, to be merged into the existing scheduler configuration.
+func RenderExtraProfileConfig(logger logr.Logger, plat platform.Platform, options RenderOptions) ([]byte, error) {
+	mf, err := GetManifests(plat, options.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	// the existing scheduler runs on its own: only the profiles matter
+	options.Replicas = 1
+	options.LeaderElection = false
+	options.PodDisruptionBudget = false
+	ret, err := mf.Render(logger, options)
+	if err != nil {
+		return nil, err
+	}
+	rendered, ok := ret.ConfigMap.Data[manifests.SchedulerConfigFileName]
+	if !ok {
+		return nil, fmt.Errorf("no scheduler config rendered")
+	}
+	return []byte(rendered), nil
+}
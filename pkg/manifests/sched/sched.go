@@ -24,19 +24,24 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 	rbacupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate/rbac"
 	schedupdate "github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate/sched"
 )
 
 const (
 	NamespaceOpenShift = "openshift-topology-aware-scheduler"
+	DefaultProfileName = "topology-aware-scheduler"
+	DefaultLeaseName   = "topology-aware-scheduler"
 )
 
 type Manifests struct {
@@ -56,6 +61,10 @@ type Manifests struct {
 	RBScheduler  *rbacv1.RoleBinding
 	DPScheduler  *appsv1.Deployment
 	ConfigMap    *corev1.ConfigMap
+	// PDBScheduler is optional
+	PDBScheduler *policyv1.PodDisruptionBudget
+	// PullSecret is optional
+	PullSecret *corev1.Secret
 	// internal fields
 	plat platform.Platform
 }
@@ -77,6 +86,8 @@ func (mf Manifests) Clone() Manifests {
 		DPScheduler:   mf.DPScheduler.DeepCopy(),
 		ConfigMap:     mf.ConfigMap.DeepCopy(),
 		RBScheduler:   mf.RBScheduler.DeepCopy(),
+		PDBScheduler:  mf.PDBScheduler.DeepCopy(),
+		PullSecret:    mf.PullSecret.DeepCopy(),
 	}
 }
 
@@ -85,8 +96,37 @@ type RenderOptions struct {
 	PullIfNotPresent  bool
 	ProfileName       string
 	CacheResyncPeriod time.Duration
+	// the cache knobs left empty keep the plugin defaults
+	CacheResyncMethod      string
+	CacheForeignPodsDetect string
+	CacheInformerMode      string
+	// ScoringStrategy unset keeps the plugin default
+	ScoringStrategy *manifests.ScoringStrategyParams
+	// PlatformVersion and the scheduler image select the configuration API version
+	PlatformVersion platform.Version
+	// Profiles, if any, replace the default profile and ProfileName. Their unset
+	// params are taken from the options above.
+	Profiles          []manifests.ConfigParams
 	CtrlPlaneAffinity bool
 	Verbose           int
+	// Namespace overrides the platform default namespace
+	Namespace         string
+	Metadata          objectupdate.MetadataOptions
+	Resources         objectupdate.ResourcesOptions
+	PriorityClassName string
+	ImagePull         objectupdate.ImagePullOptions
+	// Images unset are the defaults
+	Images images.ImageSet
+	// PodDisruptionBudget is always enabled with more than one replica.
+	// On a single replica it would block the node drains.
+	PodDisruptionBudget bool
+	// LeaderElection makes the replicas of the scheduler and of the controller
+	// elect a leader, and spreads them across the nodes. Always enabled with more
+	// than one replica. The scheduler lease defaults to DefaultLeaseName in the
+	// scheduler namespace.
+	LeaderElection bool
+	LeaseName      string
+	LeaseNamespace string
 }
 
 func (mf Manifests) Render(logger logr.Logger, options RenderOptions) (Manifests, error) {
@@ -99,21 +139,62 @@ func (mf Manifests) Render(logger logr.Logger, options RenderOptions) (Manifests
 	ret.DPController.Spec.Replicas = newInt32(replicas)
 
 	params := manifests.ConfigParams{
+		ProfileName: options.ProfileName,
 		Cache: &manifests.ConfigCacheParams{
-			ResyncPeriodSeconds: newInt64(int64(options.CacheResyncPeriod.Seconds())),
+			ResyncPeriodSeconds:   newInt64(int64(options.CacheResyncPeriod.Seconds())),
+			ResyncMethod:          newStringIfSet(options.CacheResyncMethod),
+			ForeignPodsDetectMode: newStringIfSet(options.CacheForeignPodsDetect),
+			InformerMode:          newStringIfSet(options.CacheInformerMode),
 		},
+		ScoringStrategy: options.ScoringStrategy,
 	}
 
-	err := schedupdate.SchedulerConfig(ret.ConfigMap, options.ProfileName, &params)
+	var err error
+	if len(options.Profiles) > 0 {
+		// the embedded default profile is the template of all the profiles
+		profiles := make([]manifests.ConfigParams, 0, len(options.Profiles))
+		for _, prof := range options.Profiles {
+			profiles = append(profiles, prof.WithDefaults(params))
+		}
+		err = schedupdate.SchedulerConfigProfiles(ret.ConfigMap, DefaultProfileName, profiles)
+	} else {
+		// the embedded configuration carries the default profile, which we rename if requested
+		schedulerName := DefaultProfileName
+		if options.ProfileName == "" {
+			schedulerName = "" // nothing to render, keep the embedded configuration
+		}
+		err = schedupdate.SchedulerConfig(ret.ConfigMap, schedulerName, &params)
+	}
+	if err != nil {
+		return ret, err
+	}
+
+	imgs := options.Images.WithDefaults()
+	schedImage := imgs.Scheduler
+	if orig, ok := options.Images.Origins()[schedImage]; ok {
+		schedImage = orig // the tag tells the version, the digest does not
+	}
+	apiVersion, err := ConfigAPIVersion(mf.plat, options.PlatformVersion, schedImage)
 	if err != nil {
 		return ret, err
 	}
+	logger.V(3).Info("scheduler configuration", "apiVersion", apiVersion, "platformVersion", options.PlatformVersion, "schedulerImage", schedImage)
+	if err := schedupdate.SchedulerConfigAPIVersion(ret.ConfigMap, apiVersion); err != nil {
+		return ret, err
+	}
 
-	schedupdate.SchedulerDeployment(ret.DPScheduler, options.PullIfNotPresent, options.CtrlPlaneAffinity, options.Verbose)
-	schedupdate.ControllerDeployment(ret.DPController, options.PullIfNotPresent, options.CtrlPlaneAffinity)
-	if mf.plat == platform.OpenShift {
-		ret.Namespace.Name = NamespaceOpenShift
+	schedupdate.SchedulerDeployment(ret.DPScheduler, imgs.Scheduler, options.PullIfNotPresent, options.CtrlPlaneAffinity, options.Verbose)
+	schedupdate.ControllerDeployment(ret.DPController, imgs.Controller, options.PullIfNotPresent, options.CtrlPlaneAffinity)
+	for _, dp := range []*appsv1.Deployment{ret.DPScheduler, ret.DPController} {
+		if err := objectupdate.SetPodResources(&dp.Spec.Template.Spec, options.Resources); err != nil {
+			return ret, err
+		}
+		objectupdate.SetPodPriorityClass(&dp.Spec.Template.Spec, options.PriorityClassName)
+	}
+	if !options.PodDisruptionBudget && replicas <= 1 {
+		ret.PDBScheduler = nil
 	}
+	UpdateNamespace(ret.Namespace, mf.plat, options.Namespace)
 
 	ret.SAController.Namespace = ret.Namespace.Name
 	rbacupdate.ClusterRoleBinding(ret.CRBController, ret.SAController.Name, ret.Namespace.Name)
@@ -125,26 +206,77 @@ func (mf Manifests) Render(logger logr.Logger, options RenderOptions) (Manifests
 	rbacupdate.RoleBinding(ret.RBScheduler, ret.SAScheduler.Name, ret.Namespace.Name)
 	ret.DPScheduler.Namespace = ret.Namespace.Name
 	ret.ConfigMap.Namespace = ret.Namespace.Name
+	if ret.PDBScheduler != nil {
+		ret.PDBScheduler.Namespace = ret.Namespace.Name
+	}
+
+	if options.LeaderElection || replicas > 1 {
+		leaseName := options.LeaseName
+		if leaseName == "" {
+			leaseName = DefaultLeaseName
+		}
+		leaseNamespace := options.LeaseNamespace
+		if leaseNamespace == "" {
+			leaseNamespace = ret.Namespace.Name
+		}
+		if err := schedupdate.SchedulerConfigLeaderElection(ret.ConfigMap, leaseName, leaseNamespace); err != nil {
+			return ret, err
+		}
+		rbacupdate.ClusterRoleLeases(ret.CRScheduler, leaseName)
+		// the controller picks its own lease
+		schedupdate.ControllerLeaderElection(ret.DPController)
+		rbacupdate.ClusterRoleLeases(ret.CRController)
+		schedupdate.SetReplicasAntiAffinity(ret.DPScheduler)
+		schedupdate.SetReplicasAntiAffinity(ret.DPController)
+	}
+
+	if name := options.ImagePull.SecretToCreate(); name != "" {
+		ret.PullSecret = manifests.CreatePullSecret(ret.Namespace.Name, name, options.ImagePull.DockerConfigData)
+	}
+	objectupdate.SetImagePullSecrets(ret.ToObjects(), options.ImagePull.Secrets)
+	objectupdate.SetImageOrigins(ret.ToObjects(), options.Images.Origins())
 
+	objectupdate.Metadata(ret.ToObjects(), options.Metadata)
 	return ret, nil
 }
 
+// UpdateNamespace sets the name of the scheduler namespace: the given one if not empty, the platform default otherwise.
+func UpdateNamespace(ns *corev1.Namespace, plat platform.Platform, namespace string) {
+	if namespace != "" {
+		ns.Name = namespace
+		return
+	}
+	if plat == platform.OpenShift {
+		ns.Name = NamespaceOpenShift
+	}
+}
+
 func (mf Manifests) ToObjects() []client.Object {
-	return []client.Object{
+	objs := []client.Object{
 		mf.Crd,
 		mf.Namespace,
+	}
+	if mf.PullSecret != nil {
+		objs = append(objs, mf.PullSecret)
+	}
+	objs = append(objs,
 		mf.SAScheduler,
 		mf.CRScheduler,
 		mf.CRBScheduler,
 		mf.ConfigMap,
 		mf.RBScheduler,
 		mf.DPScheduler,
+	)
+	if mf.PDBScheduler != nil {
+		objs = append(objs, mf.PDBScheduler)
+	}
+	return append(objs,
 		mf.SAController,
 		mf.CRController,
 		mf.CRBController,
 		mf.DPController,
 		mf.RBController,
-	}
+	)
 }
 
 func New(plat platform.Platform) Manifests {
@@ -181,7 +313,8 @@ func GetManifests(plat platform.Platform, namespace string) (Manifests, error) {
 	if err != nil {
 		return mf, err
 	}
-	mf.RBScheduler, err = manifests.RoleBinding(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginScheduler, namespace)
+	// the rolebindings grant access to kube-system resources, so they must stay there
+	mf.RBScheduler, err = manifests.RoleBinding(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginScheduler, "")
 	if err != nil {
 		return mf, err
 	}
@@ -190,6 +323,11 @@ func GetManifests(plat platform.Platform, namespace string) (Manifests, error) {
 		return mf, err
 	}
 
+	mf.PDBScheduler, err = manifests.PodDisruptionBudget(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginScheduler, "")
+	if err != nil {
+		return mf, err
+	}
+
 	mf.SAController, err = manifests.ServiceAccount(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginController, namespace)
 	if err != nil {
 		return mf, err
@@ -202,7 +340,7 @@ func GetManifests(plat platform.Platform, namespace string) (Manifests, error) {
 	if err != nil {
 		return mf, err
 	}
-	mf.RBController, err = manifests.RoleBinding(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginController, namespace)
+	mf.RBController, err = manifests.RoleBinding(manifests.ComponentSchedulerPlugin, manifests.SubComponentSchedulerPluginController, "")
 	if err != nil {
 		return mf, err
 	}
@@ -221,3 +359,10 @@ func newInt32(value int32) *int32 {
 func newInt64(value int64) *int64 {
 	return &value
 }
+
+func newStringIfSet(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -17,12 +17,19 @@
 package sched
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr/testr"
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 )
 
 func TestClone(t *testing.T) {
@@ -87,3 +94,298 @@ func TestRender(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderNamespace(t *testing.T) {
+	type testCase struct {
+		name      string
+		plat      platform.Platform
+		namespace string
+		expected  string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "kubernetes default",
+			plat:     platform.Kubernetes,
+			expected: "tas-scheduler",
+		},
+		{
+			name:     "openshift default",
+			plat:     platform.OpenShift,
+			expected: NamespaceOpenShift,
+		},
+		{
+			name:      "custom",
+			plat:      platform.OpenShift,
+			namespace: "foo",
+			expected:  "foo",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mf, err := GetManifests(tc.plat, "")
+			if err != nil {
+				t.Fatalf("GetManifests() failed: %v", err)
+			}
+			uMf, err := mf.Render(testr.New(t), RenderOptions{
+				Replicas:  int32(1),
+				Namespace: tc.namespace,
+			})
+			if err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+
+			if uMf.Namespace.Name != tc.expected {
+				t.Errorf("namespace %q expected %q", uMf.Namespace.Name, tc.expected)
+			}
+			for _, obj := range []client.Object{uMf.SAScheduler, uMf.SAController, uMf.DPScheduler, uMf.DPController, uMf.ConfigMap} {
+				if obj.GetNamespace() != tc.expected {
+					t.Errorf("%s namespace %q expected %q", obj.GetName(), obj.GetNamespace(), tc.expected)
+				}
+			}
+			for _, sub := range append(uMf.CRBScheduler.Subjects, uMf.RBScheduler.Subjects...) {
+				if sub.Namespace != tc.expected {
+					t.Errorf("subject %s namespace %q expected %q", sub.Name, sub.Namespace, tc.expected)
+				}
+			}
+			// grants access to the kube-system resources, must not move
+			if uMf.RBScheduler.Namespace != "kube-system" {
+				t.Errorf("rolebinding moved to namespace %q", uMf.RBScheduler.Namespace)
+			}
+		})
+	}
+}
+
+func TestRenderProfileName(t *testing.T) {
+	type testCase struct {
+		name           string
+		profileName    string
+		expectedName   string
+		expectedResync int64
+	}
+
+	testCases := []testCase{
+		{
+			name:         "default profile",
+			expectedName: DefaultProfileName,
+		},
+		{
+			name:           "renamed profile",
+			profileName:    "foo-scheduler",
+			expectedName:   "foo-scheduler",
+			expectedResync: 7,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mf, err := GetManifests(platform.Kubernetes, "")
+			if err != nil {
+				t.Fatalf("GetManifests() failed: %v", err)
+			}
+			uMf, err := mf.Render(testr.New(t), RenderOptions{
+				Replicas:          int32(1),
+				ProfileName:       tc.profileName,
+				CacheResyncPeriod: 7 * time.Second,
+			})
+			if err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+
+			profiles, err := manifests.DecodeSchedulerProfilesFromData([]byte(uMf.ConfigMap.Data[manifests.SchedulerConfigFileName]))
+			if err != nil {
+				t.Fatalf("cannot decode the rendered profiles: %v", err)
+			}
+			if len(profiles) != 1 || profiles[0].ProfileName != tc.expectedName {
+				t.Fatalf("unexpected profiles: %s", toJSON(profiles))
+			}
+			if tc.expectedResync == 0 {
+				return
+			}
+			// the params must reach the renamed profile
+			cache := profiles[0].Cache
+			if cache == nil || cache.ResyncPeriodSeconds == nil || *cache.ResyncPeriodSeconds != tc.expectedResync {
+				t.Errorf("unexpected cache params: %s", toJSON(cache))
+			}
+		})
+	}
+}
+
+func TestRenderPodDisruptionBudget(t *testing.T) {
+	type testCase struct {
+		name              string
+		options           RenderOptions
+		expectedPDB       bool
+		expectedPrioClass string
+	}
+
+	testCases := []testCase{
+		{
+			name: "defaults",
+			options: RenderOptions{
+				Replicas: int32(1),
+			},
+		},
+		{
+			name: "pdb from replicas",
+			options: RenderOptions{
+				Replicas: int32(2),
+			},
+			expectedPDB: true,
+		},
+		{
+			name: "pdb and priority class",
+			options: RenderOptions{
+				Replicas:            int32(2),
+				Namespace:           "foo",
+				PriorityClassName:   "prio",
+				PodDisruptionBudget: true,
+			},
+			expectedPDB:       true,
+			expectedPrioClass: "prio",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mf, err := GetManifests(platform.Kubernetes, "")
+			if err != nil {
+				t.Fatalf("GetManifests() failed: %v", err)
+			}
+			uMf, err := mf.Render(testr.New(t), tc.options)
+			if err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+
+			if got := uMf.PDBScheduler != nil; got != tc.expectedPDB {
+				t.Fatalf("PDB rendered=%v expected=%v", got, tc.expectedPDB)
+			}
+			found := false
+			for _, obj := range uMf.ToObjects() {
+				if obj == client.Object(uMf.PDBScheduler) {
+					found = true
+				}
+			}
+			if found != tc.expectedPDB {
+				t.Errorf("PDB in objects=%v expected=%v", found, tc.expectedPDB)
+			}
+			if tc.expectedPDB && uMf.PDBScheduler.Namespace != uMf.Namespace.Name {
+				t.Errorf("PDB namespace %q expected %q", uMf.PDBScheduler.Namespace, uMf.Namespace.Name)
+			}
+			for _, dp := range []*appsv1.Deployment{uMf.DPScheduler, uMf.DPController} {
+				if got := dp.Spec.Template.Spec.PriorityClassName; got != tc.expectedPrioClass {
+					t.Errorf("%s priority class %q expected %q", dp.Name, got, tc.expectedPrioClass)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderLeaderElection(t *testing.T) {
+	type testCase struct {
+		name          string
+		options       RenderOptions
+		expectedLease string
+	}
+
+	testCases := []testCase{
+		{
+			name: "disabled",
+			options: RenderOptions{
+				Replicas: int32(1),
+			},
+		},
+		{
+			name: "default lease",
+			options: RenderOptions{
+				Replicas:       int32(2),
+				Namespace:      "foo",
+				LeaderElection: true,
+			},
+			expectedLease: "foo/" + DefaultLeaseName,
+		},
+		{
+			name: "from replicas",
+			options: RenderOptions{
+				Replicas: int32(2),
+			},
+			expectedLease: "tas-scheduler/" + DefaultLeaseName,
+		},
+		{
+			name: "custom lease",
+			options: RenderOptions{
+				Replicas:       int32(2),
+				LeaderElection: true,
+				LeaseName:      "tas",
+				LeaseNamespace: "leases",
+			},
+			expectedLease: "leases/tas",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mf, err := GetManifests(platform.Kubernetes, "")
+			if err != nil {
+				t.Fatalf("GetManifests() failed: %v", err)
+			}
+			uMf, err := mf.Render(testr.New(t), tc.options)
+			if err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+
+			var conf struct {
+				LeaderElection struct {
+					LeaderElect       bool   `json:"leaderElect"`
+					ResourceName      string `json:"resourceName"`
+					ResourceNamespace string `json:"resourceNamespace"`
+				} `json:"leaderElection"`
+			}
+			if err := yaml.Unmarshal([]byte(uMf.ConfigMap.Data[manifests.SchedulerConfigFileName]), &conf); err != nil {
+				t.Fatalf("cannot decode the scheduler config: %v", err)
+			}
+			enabled := tc.expectedLease != ""
+			if conf.LeaderElection.LeaderElect != enabled {
+				t.Fatalf("leader election %v expected %v", conf.LeaderElection.LeaderElect, enabled)
+			}
+			if !enabled {
+				if aff := uMf.DPScheduler.Spec.Template.Spec.Affinity; aff != nil && aff.PodAntiAffinity != nil {
+					t.Errorf("unexpected anti affinity")
+				}
+				return
+			}
+			if got := conf.LeaderElection.ResourceNamespace + "/" + conf.LeaderElection.ResourceName; got != tc.expectedLease {
+				t.Errorf("lease %q expected %q", got, tc.expectedLease)
+			}
+			for _, dp := range []*appsv1.Deployment{uMf.DPScheduler, uMf.DPController} {
+				if dp.Spec.Template.Spec.Affinity == nil || dp.Spec.Template.Spec.Affinity.PodAntiAffinity == nil {
+					t.Errorf("%s missing anti affinity", dp.Name)
+				}
+			}
+			if args := uMf.DPController.Spec.Template.Spec.Containers[0].Args; !reflect.DeepEqual(args, []string{"--enableLeaderElection=true"}) {
+				t.Errorf("unexpected controller args: %v", args)
+			}
+			if !hasLeaseRule(uMf.CRScheduler.Rules, conf.LeaderElection.ResourceName) {
+				t.Errorf("scheduler can't update lease %q", conf.LeaderElection.ResourceName)
+			}
+		})
+	}
+}
+
+func hasLeaseRule(rules []rbacv1.PolicyRule, leaseName string) bool {
+	for _, rule := range rules {
+		if reflect.DeepEqual(rule.Resources, []string{"leases"}) && reflect.DeepEqual(rule.ResourceNames, []string{leaseName}) {
+			return true
+		}
+	}
+	return false
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "<ERROR>"
+	}
+	return string(data)
+}
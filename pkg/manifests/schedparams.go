@@ -18,10 +18,12 @@ package manifests
 
 import (
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/yaml"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 )
 
@@ -30,13 +32,214 @@ const (
 	SchedulerPluginName     = "NodeResourceTopologyMatch"
 )
 
+const (
+	CacheResyncAutodetect             = "Autodetect"
+	CacheResyncAll                    = "All"
+	CacheResyncOnlyExclusiveResources = "OnlyExclusiveResources"
+)
+
+const (
+	ForeignPodsDetectNone                   = "None"
+	ForeignPodsDetectAll                    = "All"
+	ForeignPodsDetectOnlyExclusiveResources = "OnlyExclusiveResources"
+)
+
+const (
+	CacheInformerShared    = "Shared"
+	CacheInformerDedicated = "Dedicated"
+)
+
+// ConfigCacheParams are the reserve cache knobs. Unset (nil) values are omitted from
+// the configuration, letting the plugin use its defaults.
 type ConfigCacheParams struct {
-	ResyncPeriodSeconds *int64
+	ResyncPeriodSeconds   *int64  `json:"resyncPeriodSeconds,omitempty"`
+	ResyncMethod          *string `json:"resyncMethod,omitempty"`
+	ForeignPodsDetectMode *string `json:"foreignPodsDetect,omitempty"`
+	InformerMode          *string `json:"informerMode,omitempty"`
+}
+
+const (
+	ScoringStrategyLeastAllocated     = "LeastAllocated"
+	ScoringStrategyMostAllocated      = "MostAllocated"
+	ScoringStrategyBalancedAllocation = "BalancedAllocation"
+	ScoringStrategyLeastNUMANodes     = "LeastNUMANodes"
+)
+
+type ScoringStrategyResourceParams struct {
+	Name   string `json:"name"`
+	Weight int64  `json:"weight"`
+}
+
+type ScoringStrategyParams struct {
+	Type string `json:"type"`
+	// Resources are optional, the plugin defaults to cpu and memory with weight 1
+	Resources []ScoringStrategyResourceParams `json:"resources,omitempty"`
 }
 
 type ConfigParams struct {
-	ProfileName string // can't be empty, so no need for pointer
-	Cache       *ConfigCacheParams
+	ProfileName     string                 `json:"schedulerName"` // can't be empty, so no need for pointer
+	Cache           *ConfigCacheParams     `json:"cache,omitempty"`
+	ScoringStrategy *ScoringStrategyParams `json:"scoringStrategy,omitempty"`
+}
+
+// WithDefaults returns a copy of the params, with the unset values taken from defaults.
+// The profile name is never taken from defaults.
+func (cp ConfigParams) WithDefaults(defaults ConfigParams) ConfigParams {
+	ret := cp
+	if defaults.Cache != nil {
+		cache := *defaults.Cache
+		if cp.Cache != nil {
+			if cp.Cache.ResyncPeriodSeconds != nil {
+				cache.ResyncPeriodSeconds = cp.Cache.ResyncPeriodSeconds
+			}
+			if cp.Cache.ResyncMethod != nil {
+				cache.ResyncMethod = cp.Cache.ResyncMethod
+			}
+			if cp.Cache.ForeignPodsDetectMode != nil {
+				cache.ForeignPodsDetectMode = cp.Cache.ForeignPodsDetectMode
+			}
+			if cp.Cache.InformerMode != nil {
+				cache.InformerMode = cp.Cache.InformerMode
+			}
+		}
+		ret.Cache = &cache
+	}
+	if ret.ScoringStrategy == nil {
+		ret.ScoringStrategy = defaults.ScoringStrategy
+	}
+	return ret
+}
+
+// DecodeSchedulerProfiles reads a list of profiles, like
+//
+//	profiles:
+//	- schedulerName: single-numa
+//	  scoringStrategy:
+//	    type: LeastNUMANodes
+//	- schedulerName: best-effort
+//	  cache:
+//	    informerMode: Shared
+//
+// and validates them.
+func DecodeSchedulerProfiles(data []byte) ([]ConfigParams, error) {
+	var conf struct {
+		Profiles []ConfigParams `json:"profiles"`
+	}
+	if err := yaml.UnmarshalStrict(data, &conf); err != nil {
+		return nil, fmt.Errorf("cannot decode the scheduler profiles: %w", err)
+	}
+	if len(conf.Profiles) == 0 {
+		return nil, fmt.Errorf("no scheduler profiles found")
+	}
+	if err := ValidateSchedulerProfiles(conf.Profiles); err != nil {
+		return nil, err
+	}
+	return conf.Profiles, nil
+}
+
+func ValidateSchedulerProfiles(profiles []ConfigParams) error {
+	seen := make(map[string]bool)
+	for _, prof := range profiles {
+		if errs := validation.IsDNS1123Subdomain(prof.ProfileName); len(errs) > 0 {
+			return fmt.Errorf("invalid scheduler profile name %q: %s", prof.ProfileName, strings.Join(errs, "; "))
+		}
+		if seen[prof.ProfileName] {
+			return fmt.Errorf("duplicate scheduler profile %q", prof.ProfileName)
+		}
+		seen[prof.ProfileName] = true
+		if prof.Cache != nil {
+			if err := ValidateCacheParams(*prof.Cache); err != nil {
+				return fmt.Errorf("scheduler profile %q: %w", prof.ProfileName, err)
+			}
+		}
+		if prof.ScoringStrategy != nil {
+			if err := ValidateScoringStrategy(*prof.ScoringStrategy); err != nil {
+				return fmt.Errorf("scheduler profile %q: %w", prof.ProfileName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func CacheResyncMethods() []string {
+	return []string{
+		CacheResyncAutodetect,
+		CacheResyncAll,
+		CacheResyncOnlyExclusiveResources,
+	}
+}
+
+func ForeignPodsDetectModes() []string {
+	return []string{
+		ForeignPodsDetectNone,
+		ForeignPodsDetectAll,
+		ForeignPodsDetectOnlyExclusiveResources,
+	}
+}
+
+func CacheInformerModes() []string {
+	return []string{
+		CacheInformerShared,
+		CacheInformerDedicated,
+	}
+}
+
+func ValidateCacheParams(cp ConfigCacheParams) error {
+	if err := validateValue("cache resync method", cp.ResyncMethod, CacheResyncMethods()); err != nil {
+		return err
+	}
+	if err := validateValue("foreign pods detect mode", cp.ForeignPodsDetectMode, ForeignPodsDetectModes()); err != nil {
+		return err
+	}
+	return validateValue("cache informer mode", cp.InformerMode, CacheInformerModes())
+}
+
+func validateValue(what string, val *string, allowed []string) error {
+	if val == nil {
+		return nil
+	}
+	for _, cur := range allowed {
+		if *val == cur {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported %s %q, supported: %s", what, *val, strings.Join(allowed, ", "))
+}
+
+func ScoringStrategyTypes() []string {
+	return []string{
+		ScoringStrategyLeastAllocated,
+		ScoringStrategyMostAllocated,
+		ScoringStrategyBalancedAllocation,
+		ScoringStrategyLeastNUMANodes,
+	}
+}
+
+func ValidateScoringStrategy(sp ScoringStrategyParams) error {
+	found := false
+	for _, typ := range ScoringStrategyTypes() {
+		if sp.Type == typ {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unsupported scoring strategy %q, supported: %s", sp.Type, strings.Join(ScoringStrategyTypes(), ", "))
+	}
+	seen := make(map[string]bool)
+	for _, res := range sp.Resources {
+		if res.Name == "" {
+			return fmt.Errorf("scoring strategy resource without name")
+		}
+		if seen[res.Name] {
+			return fmt.Errorf("duplicate scoring strategy resource %q", res.Name)
+		}
+		seen[res.Name] = true
+		if res.Weight <= 0 {
+			return fmt.Errorf("scoring strategy resource %q: non-positive weight %d", res.Name, res.Weight)
+		}
+	}
+	return nil
 }
 
 func DecodeSchedulerProfilesFromData(data []byte) ([]ConfigParams, error) {
@@ -67,10 +270,14 @@ func DecodeSchedulerProfilesFromData(data []byte) ([]ConfigParams, error) {
 		}
 
 		pluginConfigs, ok, err := unstructured.NestedSlice(profile, "pluginConfig")
-		if !ok || err != nil {
+		if err != nil {
 			klog.ErrorS(err, "failed to process unstructured data", "pluginConfig", ok)
 			return params, nil
 		}
+		if !ok {
+			// profiles not configuring any plugin can't use ours
+			continue
+		}
 		for _, plConf := range pluginConfigs {
 			pluginConf, ok := plConf.(map[string]interface{})
 			if !ok {
@@ -123,15 +330,89 @@ func extractParams(profileName string, args map[string]interface{}) (ConfigParam
 	}
 	// json quirk: we know it's int64, yet it's detected as float64
 	resyncPeriod, ok, err := unstructured.NestedFloat64(args, "cacheResyncPeriodSeconds")
-	if !ok {
-		// nothing to do
-		return params, nil
-	}
 	if err != nil {
 		return params, fmt.Errorf("cannot process field cacheResyncPeriodSeconds: %w", err)
 	}
+	if ok {
+		val := int64(resyncPeriod)
+		params.Cache.ResyncPeriodSeconds = &val
+	}
 
-	val := int64(resyncPeriod)
-	params.Cache.ResyncPeriodSeconds = &val
+	cache, ok, err := unstructured.NestedMap(args, "cache")
+	if err != nil {
+		return params, fmt.Errorf("cannot process field cache: %w", err)
+	}
+	if ok {
+		if err := extractCacheParams(cache, params.Cache); err != nil {
+			return params, err
+		}
+	}
+
+	scoringStrategy, ok, err := unstructured.NestedMap(args, "scoringStrategy")
+	if err != nil {
+		return params, fmt.Errorf("cannot process field scoringStrategy: %w", err)
+	}
+	if ok {
+		sp, err := extractScoringStrategy(scoringStrategy)
+		if err != nil {
+			return params, err
+		}
+		params.ScoringStrategy = &sp
+	}
 	return params, nil
 }
+
+func extractCacheParams(cache map[string]interface{}, cp *ConfigCacheParams) error {
+	fields := []struct {
+		name string
+		dest **string
+	}{
+		{name: "resyncMethod", dest: &cp.ResyncMethod},
+		{name: "foreignPodsDetect", dest: &cp.ForeignPodsDetectMode},
+		{name: "informerMode", dest: &cp.InformerMode},
+	}
+	for _, field := range fields {
+		val, ok, err := unstructured.NestedString(cache, field.name)
+		if err != nil {
+			return fmt.Errorf("cannot process field cache.%s: %w", field.name, err)
+		}
+		if ok {
+			*field.dest = &val
+		}
+	}
+	return nil
+}
+
+func extractScoringStrategy(scoringStrategy map[string]interface{}) (ScoringStrategyParams, error) {
+	sp := ScoringStrategyParams{}
+	typ, _, err := unstructured.NestedString(scoringStrategy, "type")
+	if err != nil {
+		return sp, fmt.Errorf("cannot process field scoringStrategy.type: %w", err)
+	}
+	sp.Type = typ
+
+	resources, _, err := unstructured.NestedSlice(scoringStrategy, "resources")
+	if err != nil {
+		return sp, fmt.Errorf("cannot process field scoringStrategy.resources: %w", err)
+	}
+	for _, res := range resources {
+		resource, ok := res.(map[string]interface{})
+		if !ok {
+			return sp, fmt.Errorf("unexpected scoringStrategy.resources data")
+		}
+		name, _, err := unstructured.NestedString(resource, "name")
+		if err != nil {
+			return sp, fmt.Errorf("cannot process field scoringStrategy.resources.name: %w", err)
+		}
+		// json quirk again
+		weight, _, err := unstructured.NestedFloat64(resource, "weight")
+		if err != nil {
+			return sp, fmt.Errorf("cannot process field scoringStrategy.resources.weight: %w", err)
+		}
+		sp.Resources = append(sp.Resources, ScoringStrategyResourceParams{
+			Name:   name,
+			Weight: int64(weight),
+		})
+	}
+	return sp, nil
+}
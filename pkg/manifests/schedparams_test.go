@@ -149,6 +149,88 @@ profiles:
 			},
 			expectedFound: true,
 		},
+		{
+			name: "cache params",
+			data: []byte(`apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- pluginConfig:
+  - args:
+      cache:
+        foreignPodsDetect: OnlyExclusiveResources
+        informerMode: Dedicated
+        resyncMethod: OnlyExclusiveResources
+      cacheResyncPeriodSeconds: 5
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: topology-aware-scheduler
+`),
+			schedulerName: "topology-aware-scheduler",
+			expectedParams: ConfigParams{
+				ProfileName: "topology-aware-scheduler",
+				Cache: &ConfigCacheParams{
+					ResyncPeriodSeconds:   newInt64(5),
+					ResyncMethod:          newString(CacheResyncOnlyExclusiveResources),
+					ForeignPodsDetectMode: newString(ForeignPodsDetectOnlyExclusiveResources),
+					InformerMode:          newString(CacheInformerDedicated),
+				},
+			},
+			expectedFound: true,
+		},
+		{
+			name: "scoring strategy",
+			data: []byte(`apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- pluginConfig:
+  - args:
+      scoringStrategy:
+        resources:
+        - name: cpu
+          weight: 2
+        - name: memory
+          weight: 1
+        type: MostAllocated
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: topology-aware-scheduler
+`),
+			schedulerName: "topology-aware-scheduler",
+			expectedParams: ConfigParams{
+				ProfileName: "topology-aware-scheduler",
+				Cache:       &ConfigCacheParams{},
+				ScoringStrategy: &ScoringStrategyParams{
+					Type: ScoringStrategyMostAllocated,
+					Resources: []ScoringStrategyResourceParams{
+						{Name: "cpu", Weight: 2},
+						{Name: "memory", Weight: 1},
+					},
+				},
+			},
+			expectedFound: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -176,6 +258,104 @@ profiles:
 	}
 }
 
+func TestValidateCacheParams(t *testing.T) {
+	type testCase struct {
+		name        string
+		params      ConfigCacheParams
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "unset",
+		},
+		{
+			name: "all set",
+			params: ConfigCacheParams{
+				ResyncMethod:          newString(CacheResyncAutodetect),
+				ForeignPodsDetectMode: newString(ForeignPodsDetectNone),
+				InformerMode:          newString(CacheInformerShared),
+			},
+		},
+		{
+			name:        "bad resync method",
+			params:      ConfigCacheParams{ResyncMethod: newString("Never")},
+			expectError: true,
+		},
+		{
+			name:        "bad foreign pods detect mode",
+			params:      ConfigCacheParams{ForeignPodsDetectMode: newString("Some")},
+			expectError: true,
+		},
+		{
+			name:        "bad informer mode",
+			params:      ConfigCacheParams{InformerMode: newString("shared")},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCacheParams(tc.params)
+			if (err != nil) != tc.expectError {
+				t.Errorf("got error %v expected error %v", err, tc.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateScoringStrategy(t *testing.T) {
+	type testCase struct {
+		name        string
+		params      ScoringStrategyParams
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:   "type only",
+			params: ScoringStrategyParams{Type: ScoringStrategyLeastNUMANodes},
+		},
+		{
+			name: "weighted resources",
+			params: ScoringStrategyParams{
+				Type:      ScoringStrategyBalancedAllocation,
+				Resources: []ScoringStrategyResourceParams{{Name: "cpu", Weight: 1}, {Name: "memory", Weight: 3}},
+			},
+		},
+		{
+			name:        "unknown type",
+			params:      ScoringStrategyParams{Type: "Random"},
+			expectError: true,
+		},
+		{
+			name: "zero weight",
+			params: ScoringStrategyParams{
+				Type:      ScoringStrategyLeastAllocated,
+				Resources: []ScoringStrategyResourceParams{{Name: "cpu", Weight: 0}},
+			},
+			expectError: true,
+		},
+		{
+			name: "duplicate resource",
+			params: ScoringStrategyParams{
+				Type:      ScoringStrategyLeastAllocated,
+				Resources: []ScoringStrategyResourceParams{{Name: "cpu", Weight: 1}, {Name: "cpu", Weight: 2}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateScoringStrategy(tc.params)
+			if (err != nil) != tc.expectError {
+				t.Errorf("got error %v expected error %v", err, tc.expectError)
+			}
+		})
+	}
+}
+
 func toJSON(v any) string {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -187,3 +367,130 @@ func toJSON(v any) string {
 func newInt64(value int64) *int64 {
 	return &value
 }
+
+func newString(value string) *string {
+	return &value
+}
+
+func TestDecodeSchedulerProfiles(t *testing.T) {
+	type testCase struct {
+		name        string
+		data        string
+		expected    []ConfigParams
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "multiple profiles",
+			data: `profiles:
+- schedulerName: single-numa
+  scoringStrategy:
+    type: LeastNUMANodes
+- schedulerName: best-effort
+  cache:
+    resyncPeriodSeconds: 10
+    informerMode: Shared
+  scoringStrategy:
+    type: LeastAllocated
+    resources:
+    - name: cpu
+      weight: 2
+`,
+			expected: []ConfigParams{
+				{
+					ProfileName: "single-numa",
+					ScoringStrategy: &ScoringStrategyParams{
+						Type: ScoringStrategyLeastNUMANodes,
+					},
+				},
+				{
+					ProfileName: "best-effort",
+					Cache: &ConfigCacheParams{
+						ResyncPeriodSeconds: newInt64(10),
+						InformerMode:        newString(CacheInformerShared),
+					},
+					ScoringStrategy: &ScoringStrategyParams{
+						Type:      ScoringStrategyLeastAllocated,
+						Resources: []ScoringStrategyResourceParams{{Name: "cpu", Weight: 2}},
+					},
+				},
+			},
+		},
+		{
+			name:        "no profiles",
+			data:        "profiles: []\n",
+			expectError: true,
+		},
+		{
+			name:        "unknown field",
+			data:        "profiles:\n- schedulerName: foo\n  scoring: MostAllocated\n",
+			expectError: true,
+		},
+		{
+			name:        "duplicate profile",
+			data:        "profiles:\n- schedulerName: foo\n- schedulerName: foo\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid profile name",
+			data:        "profiles:\n- schedulerName: Foo_Bar\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid args",
+			data:        "profiles:\n- schedulerName: foo\n  cache:\n    informerMode: Sometimes\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeSchedulerProfiles([]byte(tc.data))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %s", toJSON(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("profiles got %s expected %s", toJSON(got), toJSON(tc.expected))
+			}
+		})
+	}
+}
+
+func TestConfigParamsWithDefaults(t *testing.T) {
+	defaults := ConfigParams{
+		ProfileName: "default",
+		Cache: &ConfigCacheParams{
+			ResyncPeriodSeconds: newInt64(5),
+			InformerMode:        newString(CacheInformerDedicated),
+		},
+		ScoringStrategy: &ScoringStrategyParams{Type: ScoringStrategyMostAllocated},
+	}
+	params := ConfigParams{
+		ProfileName: "custom",
+		Cache: &ConfigCacheParams{
+			InformerMode: newString(CacheInformerShared),
+		},
+	}
+	expected := ConfigParams{
+		ProfileName: "custom",
+		Cache: &ConfigCacheParams{
+			ResyncPeriodSeconds: newInt64(5),
+			InformerMode:        newString(CacheInformerShared),
+		},
+		ScoringStrategy: &ScoringStrategyParams{Type: ScoringStrategyMostAllocated},
+	}
+	got := params.WithDefaults(defaults)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("params got %s expected %s", toJSON(got), toJSON(expected))
+	}
+	if *defaults.Cache.InformerMode != CacheInformerDedicated {
+		t.Errorf("defaults modified: %s", toJSON(defaults))
+	}
+}
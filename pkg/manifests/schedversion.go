@@ -0,0 +1,92 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package manifests
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SchedulerConfigGroup = "kubescheduler.config.k8s.io"
+
+	SchedulerConfigAPIVersionV1beta2 = SchedulerConfigGroup + "/v1beta2"
+	SchedulerConfigAPIVersionV1beta3 = SchedulerConfigGroup + "/v1beta3"
+	SchedulerConfigAPIVersionV1      = SchedulerConfigGroup + "/v1"
+)
+
+// UnknownKubeMinor marks a kubernetes minor version which can't be detected
+const UnknownKubeMinor = -1
+
+type schedulerConfigAPIVersion struct {
+	apiVersion string
+	// kubernetes 1.x minor versions accepting the apiVersion
+	firstMinor int
+	lastMinor  int // 0 means still supported
+}
+
+// oldest first
+var schedulerConfigAPIVersions = []schedulerConfigAPIVersion{
+	{apiVersion: SchedulerConfigAPIVersionV1beta2, firstMinor: 22, lastMinor: 27},
+	{apiVersion: SchedulerConfigAPIVersionV1beta3, firstMinor: 23, lastMinor: 28},
+	{apiVersion: SchedulerConfigAPIVersionV1, firstMinor: 25},
+}
+
+// SchedulerConfigAPIVersions returns the KubeSchedulerConfiguration API versions accepted
+// by the kube-scheduler of the given kubernetes 1.x minor version, oldest first.
+func SchedulerConfigAPIVersions(kubeMinor int) []string {
+	var ret []string
+	for _, ver := range schedulerConfigAPIVersions {
+		if kubeMinor < ver.firstMinor || (ver.lastMinor > 0 && kubeMinor > ver.lastMinor) {
+			continue
+		}
+		ret = append(ret, ver.apiVersion)
+	}
+	return ret
+}
+
+// SelectSchedulerConfigAPIVersion returns the newest KubeSchedulerConfiguration API version accepted
+// both by the kube-scheduler matching the cluster and by the one in the scheduler image, or an error
+// if there is none. UnknownKubeMinor adds no constraint; returns empty if both are unknown.
+func SelectSchedulerConfigAPIVersion(clusterMinor, schedulerMinor int) (string, error) {
+	if clusterMinor == UnknownKubeMinor && schedulerMinor == UnknownKubeMinor {
+		return "", nil
+	}
+	if clusterMinor == UnknownKubeMinor {
+		clusterMinor = schedulerMinor
+	}
+	if schedulerMinor == UnknownKubeMinor {
+		schedulerMinor = clusterMinor
+	}
+	clusterVersions := SchedulerConfigAPIVersions(clusterMinor)
+	schedulerVersions := SchedulerConfigAPIVersions(schedulerMinor)
+	for idx := len(schedulerVersions) - 1; idx >= 0; idx-- {
+		for _, ver := range clusterVersions {
+			if ver == schedulerVersions[idx] {
+				return ver, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no scheduler configuration API version works with both the cluster (kubernetes 1.%d: %s) and the scheduler image (kubernetes 1.%d: %s)", clusterMinor, describeAPIVersions(clusterVersions), schedulerMinor, describeAPIVersions(schedulerVersions))
+}
+
+func describeAPIVersions(apiVersions []string) string {
+	if len(apiVersions) == 0 {
+		return "none supported"
+	}
+	return strings.Join(apiVersions, ", ")
+}
@@ -0,0 +1,100 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package manifests
+
+import (
+	"testing"
+)
+
+func TestSelectSchedulerConfigAPIVersion(t *testing.T) {
+	type testCase struct {
+		name           string
+		clusterMinor   int
+		schedulerMinor int
+		expected       string
+		expectError    bool
+	}
+
+	testCases := []testCase{
+		{
+			name:           "both unknown",
+			clusterMinor:   UnknownKubeMinor,
+			schedulerMinor: UnknownKubeMinor,
+			expected:       "",
+		},
+		{
+			name:           "only v1beta2",
+			clusterMinor:   22,
+			schedulerMinor: 22,
+			expected:       SchedulerConfigAPIVersionV1beta2,
+		},
+		{
+			name:           "older cluster bounds the scheduler",
+			clusterMinor:   24,
+			schedulerMinor: 26,
+			expected:       SchedulerConfigAPIVersionV1beta3,
+		},
+		{
+			name:           "v1",
+			clusterMinor:   26,
+			schedulerMinor: 26,
+			expected:       SchedulerConfigAPIVersionV1,
+		},
+		{
+			name:           "unknown scheduler",
+			clusterMinor:   23,
+			schedulerMinor: UnknownKubeMinor,
+			expected:       SchedulerConfigAPIVersionV1beta3,
+		},
+		{
+			name:           "unknown cluster",
+			clusterMinor:   UnknownKubeMinor,
+			schedulerMinor: 29,
+			expected:       SchedulerConfigAPIVersionV1,
+		},
+		{
+			name:           "no common version",
+			clusterMinor:   22,
+			schedulerMinor: 28,
+			expectError:    true,
+		},
+		{
+			name:           "too old",
+			clusterMinor:   21,
+			schedulerMinor: 21,
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SelectSchedulerConfigAPIVersion(tc.clusterMinor, tc.schedulerMinor)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %q expected %q", got, tc.expected)
+			}
+		})
+	}
+}
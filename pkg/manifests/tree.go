@@ -0,0 +1,130 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package manifests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const IndexFileName = "index.txt"
+
+// Component is a group of objects which is rendered in its own directory
+type Component struct {
+	Name    string
+	Objects []client.Object
+}
+
+// WriteTree writes the components in dir, one directory per component holding one file
+// per object, plus an index file listing the object files, relative to dir, in apply order.
+// dir must be empty or missing, so the tree holds only the objects in the index.
+func WriteTree(dir string, comps []Component) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("output directory %q is not empty", dir)
+	}
+
+	var sb strings.Builder
+	for _, comp := range comps {
+		fileNames, err := WriteObjects(filepath.Join(dir, comp.Name), comp.Objects)
+		if err != nil {
+			return fmt.Errorf("cannot write component %q: %w", comp.Name, err)
+		}
+		for _, fileName := range fileNames {
+			// always use forward slashes, like kubectl and kustomize expect
+			sb.WriteString(comp.Name + "/" + fileName + "\n")
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, IndexFileName), []byte(sb.String()), 0644)
+}
+
+// WriteObjects writes one file per object in dir and returns the file names in the objects order
+func WriteObjects(dir string, objs []client.Object) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	fileNames, err := ObjectFileNames(objs)
+	if err != nil {
+		return nil, err
+	}
+	for idx, obj := range objs {
+		data, err := SerializeObjectToData(obj)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, fileNames[idx]), data, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return fileNames, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// ObjectFileNames returns the file names, in the same order, to store the given objects.
+// Names are `<kind>-<name>.yaml`; the namespace is added to tell apart same-named objects.
+func ObjectFileNames(objs []client.Object) ([]string, error) {
+	fileNames := make([]string, 0, len(objs))
+	seen := make(map[string]bool)
+	seenObjs := make(map[string]bool)
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, k8sscheme.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		objKey := gvk.Kind + "/" + obj.GetNamespace() + "/" + obj.GetName()
+		if seenObjs[objKey] {
+			return nil, fmt.Errorf("duplicate object %s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+		}
+		seenObjs[objKey] = true
+
+		fileName := makeFileName(gvk.Kind, obj.GetName())
+		if seen[fileName] {
+			fileName = makeFileName(gvk.Kind, obj.GetNamespace(), obj.GetName())
+		}
+		if seen[fileName] {
+			return nil, fmt.Errorf("cannot find an unique file name for %s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+		}
+		seen[fileName] = true
+		fileNames = append(fileNames, fileName)
+	}
+	return fileNames, nil
+}
+
+func makeFileName(items ...string) string {
+	var parts []string
+	for _, item := range items {
+		part := strings.Trim(unsafeChars.ReplaceAllString(strings.ToLower(item), "-"), "-")
+		if part == "" {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "-") + ".yaml"
+}
@@ -0,0 +1,133 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package manifests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestObjectFileNames(t *testing.T) {
+	type testCase struct {
+		name        string
+		objs        []client.Object
+		expected    []string
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "plain names",
+			objs: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tas-scheduler"}},
+				&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "system:topology-updater"}},
+			},
+			expected: []string{
+				"namespace-tas-scheduler.yaml",
+				"clusterrole-system-topology-updater.yaml",
+			},
+		},
+		{
+			name: "same name different namespaces",
+			objs: []client.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "rte"}},
+			},
+			expected: []string{
+				"serviceaccount-rte.yaml",
+				"serviceaccount-bar-rte.yaml",
+			},
+		},
+		{
+			name: "duplicate",
+			objs: []client.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte"}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ObjectFileNames(tc.objs)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("file names mismatch: got %v expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	dir := t.TempDir()
+	comps := []Component{
+		{
+			Name: "api",
+			Objects: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+			},
+		},
+		{
+			Name: "topology-updater",
+			Objects: []client.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte"}},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte-config"}},
+			},
+		},
+	}
+
+	if err := WriteTree(dir, comps); err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		t.Fatalf("cannot read the index: %v", err)
+	}
+	expected := "api/namespace-foo.yaml\ntopology-updater/serviceaccount-rte.yaml\ntopology-updater/configmap-rte-config.yaml\n"
+	if string(data) != expected {
+		t.Errorf("index mismatch:\ngot=%s\nexpected=%s", data, expected)
+	}
+	for _, fileName := range strings.Split(strings.TrimSpace(expected), "\n") {
+		if _, err := os.Stat(filepath.Join(dir, fileName)); err != nil {
+			t.Errorf("missing object file %q: %v", fileName, err)
+		}
+	}
+
+	// stale files would not be in the index
+	if err := WriteTree(dir, comps); err == nil {
+		t.Errorf("unexpected success writing on a non-empty directory")
+	}
+}
@@ -16,7 +16,12 @@
 
 package objectupdate
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 const (
 	NodeRoleControlPlane           = "node-role.kubernetes.io/control-plane"
@@ -61,6 +66,35 @@ func SetPodSchedulerAffinityOnControlPlane(podSpec *corev1.PodSpec) {
 	}
 }
 
+// SetPodAntiAffinity makes the replicas of the pods with the given labels prefer different nodes.
+func SetPodAntiAffinity(podSpec *corev1.PodSpec, podLabels map[string]string) {
+	if podSpec == nil || len(podLabels) == 0 {
+		return
+	}
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.PodAntiAffinity == nil {
+		podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	term := corev1.WeightedPodAffinityTerm{
+		Weight: 100,
+		PodAffinityTerm: corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: podLabels,
+			},
+			TopologyKey: corev1.LabelHostname,
+		},
+	}
+	terms := podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	for _, cur := range terms {
+		if reflect.DeepEqual(cur, term) {
+			return
+		}
+	}
+	podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(terms, term)
+}
+
 func findTolerationByKey(tolerations []corev1.Toleration, key string) *corev1.Toleration {
 	for idx := range tolerations {
 		toleration := &tolerations[idx]
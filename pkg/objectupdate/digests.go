@@ -0,0 +1,96 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+)
+
+// AnnotationOriginalImagePrefix, followed by the container name, records the reference
+// of the container image before its digest was pinned
+const AnnotationOriginalImagePrefix = "original-image.topology.node.k8s.io/"
+
+// SetImageOrigins annotates the pod templates among objs with the original reference
+// of the containers whose image is pinned. origins maps the pinned images to the original references.
+func SetImageOrigins(objs []client.Object, origins map[string]string) {
+	if len(origins) == 0 {
+		return
+	}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			annotateImageOrigins(&o.Spec.Template, origins)
+		case *appsv1.DaemonSet:
+			annotateImageOrigins(&o.Spec.Template, origins)
+		}
+	}
+}
+
+func annotateImageOrigins(tmpl *corev1.PodTemplateSpec, origins map[string]string) {
+	conts := append(append([]corev1.Container{}, tmpl.Spec.InitContainers...), tmpl.Spec.Containers...)
+	for _, cnt := range conts {
+		origin, ok := origins[cnt.Image]
+		if !ok {
+			continue
+		}
+		if tmpl.Annotations == nil {
+			tmpl.Annotations = make(map[string]string)
+		}
+		tmpl.Annotations[AnnotationOriginalImagePrefix+cnt.Name] = origin
+	}
+}
+
+// PinImages replaces, in the pod templates among objs, the images found in digests with their
+// digest references, annotating the original ones. digests maps the images to their digests.
+func PinImages(objs []client.Object, digests map[string]string) {
+	if len(digests) == 0 {
+		return
+	}
+	origins := make(map[string]string)
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			pinPodImages(&o.Spec.Template.Spec, digests, origins)
+		case *appsv1.DaemonSet:
+			pinPodImages(&o.Spec.Template.Spec, digests, origins)
+		}
+	}
+	SetImageOrigins(objs, origins)
+}
+
+func pinPodImages(podSpec *corev1.PodSpec, digests, origins map[string]string) {
+	for _, conts := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for idx := range conts {
+			image := conts[idx].Image
+			digest, ok := digests[image]
+			if !ok {
+				continue
+			}
+			pinned := images.Repository(image) + "@" + digest
+			if pinned == image {
+				continue
+			}
+			conts[idx].Image = pinned
+			origins[pinned] = image
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSetImageOrigins(t *testing.T) {
+	type testCase struct {
+		name     string
+		origins  map[string]string
+		expected map[string]string
+	}
+
+	testCases := []testCase{
+		{
+			name: "no origins",
+		},
+		{
+			name: "pinned images",
+			origins: map[string]string{
+				"quay.io/tas/rte@sha256:1234":       "quay.io/tas/rte:v1",
+				"registry.k8s.io/pause@sha256:5678": "registry.k8s.io/pause:3.9",
+			},
+			expected: map[string]string{
+				AnnotationOriginalImagePrefix + "rte":   "quay.io/tas/rte:v1",
+				AnnotationOriginalImagePrefix + "pause": "registry.k8s.io/pause:3.9",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{}
+			ds.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "pause", Image: "registry.k8s.io/pause@sha256:5678"}}
+			ds.Spec.Template.Spec.Containers = []corev1.Container{
+				{Name: "rte", Image: "quay.io/tas/rte@sha256:1234"},
+				{Name: "other", Image: "quay.io/tas/other:v1"},
+			}
+
+			SetImageOrigins([]client.Object{ds, &corev1.ConfigMap{}}, tc.origins)
+
+			if got := ds.Spec.Template.Annotations; !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got annotations %v expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPinImages(t *testing.T) {
+	type testCase struct {
+		name                string
+		digests             map[string]string
+		expectedImages      []string
+		expectedAnnotations map[string]string
+	}
+
+	testCases := []testCase{
+		{
+			name:           "no digests",
+			expectedImages: []string{"registry.k8s.io/pause:3.9", "quay.io/tas/rte:v1", "quay.io/tas/other@sha256:abcd"},
+		},
+		{
+			name: "pinned images",
+			digests: map[string]string{
+				"quay.io/tas/rte:v1":            "sha256:1234",
+				"registry.k8s.io/pause:3.9":     "sha256:5678",
+				"quay.io/tas/other@sha256:abcd": "sha256:abcd",
+			},
+			expectedImages: []string{"registry.k8s.io/pause@sha256:5678", "quay.io/tas/rte@sha256:1234", "quay.io/tas/other@sha256:abcd"},
+			expectedAnnotations: map[string]string{
+				AnnotationOriginalImagePrefix + "rte":   "quay.io/tas/rte:v1",
+				AnnotationOriginalImagePrefix + "pause": "registry.k8s.io/pause:3.9",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{}
+			ds.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "pause", Image: "registry.k8s.io/pause:3.9"}}
+			ds.Spec.Template.Spec.Containers = []corev1.Container{
+				{Name: "rte", Image: "quay.io/tas/rte:v1"},
+				{Name: "other", Image: "quay.io/tas/other@sha256:abcd"},
+			}
+
+			PinImages([]client.Object{ds, &corev1.ConfigMap{}}, tc.digests)
+
+			podSpec := ds.Spec.Template.Spec
+			got := []string{podSpec.InitContainers[0].Image, podSpec.Containers[0].Image, podSpec.Containers[1].Image}
+			if !reflect.DeepEqual(got, tc.expectedImages) {
+				t.Errorf("got images %v expected %v", got, tc.expectedImages)
+			}
+			if got := ds.Spec.Template.Annotations; !reflect.DeepEqual(got, tc.expectedAnnotations) {
+				t.Errorf("got annotations %v expected %v", got, tc.expectedAnnotations)
+			}
+		})
+	}
+}
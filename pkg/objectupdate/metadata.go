@@ -0,0 +1,238 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"fmt"
+	"strings"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MetadataOptions are applied uniformly to all the objects of a component
+type MetadataOptions struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	// NamePrefix and NameSuffix are added to the names of all the objects but
+	// CRDs, whose name is fixed, and Namespaces and PriorityClasses, which have
+	// their own options.
+	NamePrefix string
+	NameSuffix string
+}
+
+func (mo MetadataOptions) IsEmpty() bool {
+	return len(mo.Labels) == 0 && len(mo.Annotations) == 0 && mo.NamePrefix == "" && mo.NameSuffix == ""
+}
+
+type objectKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// Metadata applies the options to objs, which are modified in place. Pod templates
+// get the labels too. The references among objs, like ServiceAccount names, ConfigMap
+// volumes, image pull secrets, RBAC subjects and SCC users, are updated to follow the renames.
+func Metadata(objs []client.Object, opts MetadataOptions) {
+	if opts.IsEmpty() {
+		return
+	}
+
+	renamed := make(map[objectKey]string)
+	for _, obj := range objs {
+		updateMetadata(obj, opts)
+		if !isRenameable(obj) {
+			continue
+		}
+		newName := opts.NamePrefix + obj.GetName() + opts.NameSuffix
+		if key, ok := referenceKey(obj); ok {
+			renamed[key] = newName
+		}
+		obj.SetName(newName)
+	}
+
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *corev1.ServiceAccount:
+			updatePullSecrets(o.ImagePullSecrets, o.Namespace, renamed)
+		case *appsv1.Deployment:
+			updatePodTemplate(&o.Spec.Template, o.Namespace, opts.Labels, renamed)
+		case *appsv1.DaemonSet:
+			updatePodTemplate(&o.Spec.Template, o.Namespace, opts.Labels, renamed)
+		case *rbacv1.RoleBinding:
+			o.RoleRef.Name = renamedRole(o.RoleRef, o.Namespace, renamed)
+			updateSubjects(o.Subjects, renamed)
+		case *rbacv1.ClusterRoleBinding:
+			o.RoleRef.Name = renamedRole(o.RoleRef, "", renamed)
+			updateSubjects(o.Subjects, renamed)
+		case *securityv1.SecurityContextConstraints:
+			for idx, user := range o.Users {
+				o.Users[idx] = renamedServiceAccountUser(user, renamed)
+			}
+		}
+	}
+}
+
+func updateMetadata(obj client.Object, opts MetadataOptions) {
+	if len(opts.Labels) > 0 {
+		obj.SetLabels(mergeMaps(obj.GetLabels(), opts.Labels))
+	}
+	if len(opts.Annotations) > 0 {
+		obj.SetAnnotations(mergeMaps(obj.GetAnnotations(), opts.Annotations))
+	}
+}
+
+func isRenameable(obj client.Object) bool {
+	switch obj.(type) {
+	case *apiextensionv1.CustomResourceDefinition, *corev1.Namespace, *schedulingv1.PriorityClass:
+		return false
+	default:
+		return true
+	}
+}
+
+// referenceKey returns the key of the objects which can be referenced by other objects
+func referenceKey(obj client.Object) (objectKey, bool) {
+	switch obj.(type) {
+	case *corev1.ServiceAccount:
+		return objectKey{kind: "ServiceAccount", namespace: obj.GetNamespace(), name: obj.GetName()}, true
+	case *corev1.ConfigMap:
+		return objectKey{kind: "ConfigMap", namespace: obj.GetNamespace(), name: obj.GetName()}, true
+	case *corev1.Secret:
+		return objectKey{kind: "Secret", namespace: obj.GetNamespace(), name: obj.GetName()}, true
+	case *rbacv1.Role:
+		return objectKey{kind: "Role", namespace: obj.GetNamespace(), name: obj.GetName()}, true
+	case *rbacv1.ClusterRole:
+		return objectKey{kind: "ClusterRole", name: obj.GetName()}, true
+	default:
+		return objectKey{}, false
+	}
+}
+
+func updatePodTemplate(tmpl *corev1.PodTemplateSpec, namespace string, labels map[string]string, renamed map[objectKey]string) {
+	if len(labels) > 0 {
+		tmpl.Labels = mergeMaps(tmpl.Labels, labels)
+	}
+
+	podSpec := &tmpl.Spec
+	if name, ok := renamed[objectKey{kind: "ServiceAccount", namespace: namespace, name: podSpec.ServiceAccountName}]; ok {
+		podSpec.ServiceAccountName = name
+	}
+	// still used by some of our manifests
+	if name, ok := renamed[objectKey{kind: "ServiceAccount", namespace: namespace, name: podSpec.DeprecatedServiceAccount}]; ok {
+		podSpec.DeprecatedServiceAccount = name
+	}
+	updatePullSecrets(podSpec.ImagePullSecrets, namespace, renamed)
+	for idx := range podSpec.Volumes {
+		cmSrc := podSpec.Volumes[idx].ConfigMap
+		if cmSrc == nil {
+			continue
+		}
+		cmSrc.Name = renamedConfigMap(cmSrc.Name, namespace, renamed)
+	}
+	for _, cnts := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for idx := range cnts {
+			updateContainerEnv(&cnts[idx], namespace, renamed)
+		}
+	}
+}
+
+func updateContainerEnv(cnt *corev1.Container, namespace string, renamed map[objectKey]string) {
+	for idx := range cnt.EnvFrom {
+		if ref := cnt.EnvFrom[idx].ConfigMapRef; ref != nil {
+			ref.Name = renamedConfigMap(ref.Name, namespace, renamed)
+		}
+	}
+	for idx := range cnt.Env {
+		src := cnt.Env[idx].ValueFrom
+		if src == nil || src.ConfigMapKeyRef == nil {
+			continue
+		}
+		src.ConfigMapKeyRef.Name = renamedConfigMap(src.ConfigMapKeyRef.Name, namespace, renamed)
+	}
+}
+
+func renamedConfigMap(name, namespace string, renamed map[objectKey]string) string {
+	if newName, ok := renamed[objectKey{kind: "ConfigMap", namespace: namespace, name: name}]; ok {
+		return newName
+	}
+	return name
+}
+
+func updatePullSecrets(refs []corev1.LocalObjectReference, namespace string, renamed map[objectKey]string) {
+	for idx := range refs {
+		if newName, ok := renamed[objectKey{kind: "Secret", namespace: namespace, name: refs[idx].Name}]; ok {
+			refs[idx].Name = newName
+		}
+	}
+}
+
+func renamedRole(ref rbacv1.RoleRef, namespace string, renamed map[objectKey]string) string {
+	key := objectKey{kind: ref.Kind, name: ref.Name}
+	if ref.Kind == "Role" {
+		key.namespace = namespace
+	}
+	if newName, ok := renamed[key]; ok {
+		return newName
+	}
+	return ref.Name
+}
+
+func updateSubjects(subjects []rbacv1.Subject, renamed map[objectKey]string) {
+	for idx := range subjects {
+		sub := &subjects[idx]
+		if sub.Kind != rbacv1.ServiceAccountKind {
+			continue
+		}
+		if newName, ok := renamed[objectKey{kind: "ServiceAccount", namespace: sub.Namespace, name: sub.Name}]; ok {
+			sub.Name = newName
+		}
+	}
+}
+
+const serviceAccountUserPrefix = "system:serviceaccount:"
+
+func renamedServiceAccountUser(user string, renamed map[objectKey]string) string {
+	if !strings.HasPrefix(user, serviceAccountUserPrefix) {
+		return user
+	}
+	items := strings.SplitN(strings.TrimPrefix(user, serviceAccountUserPrefix), ":", 2)
+	if len(items) != 2 {
+		return user
+	}
+	if newName, ok := renamed[objectKey{kind: "ServiceAccount", namespace: items[0], name: items[1]}]; ok {
+		return fmt.Sprintf("%s%s:%s", serviceAccountUserPrefix, items[0], newName)
+	}
+	return user
+}
+
+func mergeMaps(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for key, val := range src {
+		dst[key] = val
+	}
+	return dst
+}
@@ -0,0 +1,159 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"reflect"
+	"testing"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestMetadata(t *testing.T) {
+	type testCase struct {
+		name            string
+		opts            MetadataOptions
+		expectedNames   []string
+		expectedLabels  map[string]string
+		expectedSA      string
+		expectedCM      string
+		expectedRoleRef string
+		expectedSubject string
+		expectedUser    string
+	}
+
+	testCases := []testCase{
+		{
+			name:            "empty options",
+			expectedNames:   []string{"foos.example.com", "ns", "sa", "cm", "cr", "crb", "ds", "scc"},
+			expectedSA:      "sa",
+			expectedCM:      "cm",
+			expectedRoleRef: "cr",
+			expectedSubject: "sa",
+			expectedUser:    "system:serviceaccount:ns:sa",
+		},
+		{
+			name: "labels only",
+			opts: MetadataOptions{
+				Labels: map[string]string{"team": "foo"},
+			},
+			expectedNames:   []string{"foos.example.com", "ns", "sa", "cm", "cr", "crb", "ds", "scc"},
+			expectedLabels:  map[string]string{"team": "foo"},
+			expectedSA:      "sa",
+			expectedCM:      "cm",
+			expectedRoleRef: "cr",
+			expectedSubject: "sa",
+			expectedUser:    "system:serviceaccount:ns:sa",
+		},
+		{
+			name: "prefix and suffix",
+			opts: MetadataOptions{
+				NamePrefix: "p-",
+				NameSuffix: "-s",
+			},
+			expectedNames:   []string{"foos.example.com", "ns", "p-sa-s", "p-cm-s", "p-cr-s", "p-crb-s", "p-ds-s", "p-scc-s"},
+			expectedSA:      "p-sa-s",
+			expectedCM:      "p-cm-s",
+			expectedRoleRef: "p-cr-s",
+			expectedSubject: "p-sa-s",
+			expectedUser:    "system:serviceaccount:ns:p-sa-s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ds"},
+				Spec: appsv1.DaemonSetSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							ServiceAccountName: "sa",
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{Name: "cm"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			crb := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "crb"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cr"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "ns", Name: "sa"}},
+			}
+			scc := &securityv1.SecurityContextConstraints{
+				ObjectMeta: metav1.ObjectMeta{Name: "scc"},
+				Users:      []string{"system:serviceaccount:ns:sa"},
+			}
+			objs := []client.Object{
+				&apiextensionv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "sa"}},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm"}},
+				&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "cr"}},
+				crb,
+				ds,
+				scc,
+			}
+
+			Metadata(objs, tc.opts)
+
+			var names []string
+			for _, obj := range objs {
+				names = append(names, obj.GetName())
+				if len(tc.expectedLabels) > 0 && !reflect.DeepEqual(obj.GetLabels(), tc.expectedLabels) {
+					t.Errorf("%q labels %v expected %v", obj.GetName(), obj.GetLabels(), tc.expectedLabels)
+				}
+			}
+			if !reflect.DeepEqual(names, tc.expectedNames) {
+				t.Errorf("names %v expected %v", names, tc.expectedNames)
+			}
+			if len(tc.expectedLabels) > 0 && !reflect.DeepEqual(ds.Spec.Template.Labels, tc.expectedLabels) {
+				t.Errorf("pod template labels %v expected %v", ds.Spec.Template.Labels, tc.expectedLabels)
+			}
+			podSpec := ds.Spec.Template.Spec
+			if podSpec.ServiceAccountName != tc.expectedSA {
+				t.Errorf("service account %q expected %q", podSpec.ServiceAccountName, tc.expectedSA)
+			}
+			if podSpec.Volumes[0].ConfigMap.Name != tc.expectedCM {
+				t.Errorf("configmap volume %q expected %q", podSpec.Volumes[0].ConfigMap.Name, tc.expectedCM)
+			}
+			if crb.RoleRef.Name != tc.expectedRoleRef {
+				t.Errorf("roleRef %q expected %q", crb.RoleRef.Name, tc.expectedRoleRef)
+			}
+			if crb.Subjects[0].Name != tc.expectedSubject {
+				t.Errorf("subject %q expected %q", crb.Subjects[0].Name, tc.expectedSubject)
+			}
+			if scc.Users[0] != tc.expectedUser {
+				t.Errorf("SCC user %q expected %q", scc.Users[0], tc.expectedUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+)
+
+// SetRegistryMirror makes the pod templates among objs pull all the images from the mirror, see images.MirrorImage.
+func SetRegistryMirror(objs []client.Object, mirror string) {
+	if mirror == "" {
+		return
+	}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			mirrorPodImages(&o.Spec.Template.Spec, mirror)
+		case *appsv1.DaemonSet:
+			mirrorPodImages(&o.Spec.Template.Spec, mirror)
+		}
+	}
+}
+
+func mirrorPodImages(podSpec *corev1.PodSpec, mirror string) {
+	for idx := range podSpec.InitContainers {
+		podSpec.InitContainers[idx].Image = images.MirrorImage(podSpec.InitContainers[idx].Image, mirror)
+	}
+	for idx := range podSpec.Containers {
+		podSpec.Containers[idx].Image = images.MirrorImage(podSpec.Containers[idx].Image, mirror)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSetRegistryMirror(t *testing.T) {
+	type testCase struct {
+		name     string
+		mirror   string
+		expected []string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "no mirror",
+			expected: []string{"registry.k8s.io/pause:3.9", "quay.io/tas/rte:v1", "quay.io/tas/sched:v1"},
+		},
+		{
+			name:     "mirror",
+			mirror:   "internal.lan:5000",
+			expected: []string{"internal.lan:5000/pause:3.9", "internal.lan:5000/tas/rte:v1", "internal.lan:5000/tas/sched:v1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{}
+			ds.Spec.Template.Spec.InitContainers = []corev1.Container{{Image: "registry.k8s.io/pause:3.9"}}
+			ds.Spec.Template.Spec.Containers = []corev1.Container{{Image: "quay.io/tas/rte:v1"}}
+			dp := &appsv1.Deployment{}
+			dp.Spec.Template.Spec.Containers = []corev1.Container{{Image: "quay.io/tas/sched:v1"}}
+
+			SetRegistryMirror([]client.Object{ds, dp, &corev1.ConfigMap{}}, tc.mirror)
+
+			got := []string{
+				ds.Spec.Template.Spec.InitContainers[0].Image,
+				ds.Spec.Template.Spec.Containers[0].Image,
+				dp.Spec.Template.Spec.Containers[0].Image,
+			}
+			for idx := range got {
+				if got[idx] != tc.expected[idx] {
+					t.Errorf("image %d: got %q expected %q", idx, got[idx], tc.expected[idx])
+				}
+			}
+		})
+	}
+}
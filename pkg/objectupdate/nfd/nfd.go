@@ -24,7 +24,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/flagcodec"
-	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 )
@@ -51,10 +50,10 @@ func UpdaterDaemonSet(ds *appsv1.DaemonSet, opts objectupdate.DaemonSetOptions)
 
 		c.Args = flags.Argv()
 
-		c.Image = images.NodeFeatureDiscoveryImage
+		c.Image = opts.Images.WithDefaults().NodeFeatureDiscovery
 	}
 
-	if opts.NodeSelector != nil {
-		ds.Spec.Template.Spec.NodeSelector = opts.NodeSelector.MatchLabels
-	}
+	objectupdate.SetPodNodeSelector(&ds.Spec.Template.Spec, opts.NodeSelector)
+	objectupdate.SetPodTolerations(&ds.Spec.Template.Spec, opts.Tolerations)
+	objectupdate.SetPodPriorityClass(&ds.Spec.Template.Spec, opts.PriorityClassName)
 }
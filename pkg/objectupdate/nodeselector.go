@@ -0,0 +1,102 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetPodNodeSelector makes the pod run only on the nodes matching the given selector.
+// MatchLabels become the pod node selector; MatchExpressions, which the pod node selector
+// cannot express, are added to every required node affinity term.
+func SetPodNodeSelector(podSpec *corev1.PodSpec, sel *metav1.LabelSelector) {
+	if podSpec == nil || sel == nil {
+		return
+	}
+
+	if len(sel.MatchLabels) > 0 {
+		podSpec.NodeSelector = make(map[string]string, len(sel.MatchLabels))
+		for key, val := range sel.MatchLabels {
+			podSpec.NodeSelector[key] = val
+		}
+	}
+
+	if len(sel.MatchExpressions) == 0 {
+		return
+	}
+
+	var reqs []corev1.NodeSelectorRequirement
+	for _, expr := range sel.MatchExpressions {
+		reqs = append(reqs, corev1.NodeSelectorRequirement{
+			Key:      expr.Key,
+			Operator: corev1.NodeSelectorOperator(expr.Operator),
+			Values:   append([]string{}, expr.Values...),
+		})
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	nodeSel := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(nodeSel.NodeSelectorTerms) == 0 {
+		nodeSel.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	// terms are ORed, requirements within a term are ANDed
+	for idx := range nodeSel.NodeSelectorTerms {
+		term := &nodeSel.NodeSelectorTerms[idx]
+		term.MatchExpressions = append(term.MatchExpressions, reqs...)
+	}
+}
+
+// SetPodTolerations adds the given tolerations to the pod, replacing
+// the existing tolerations with the same key and effect.
+func SetPodTolerations(podSpec *corev1.PodSpec, tolerations []corev1.Toleration) {
+	if podSpec == nil {
+		return
+	}
+	for _, toleration := range tolerations {
+		replaced := false
+		for idx := range podSpec.Tolerations {
+			cur := &podSpec.Tolerations[idx]
+			if cur.Key == toleration.Key && cur.Effect == toleration.Effect {
+				*cur = toleration
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			podSpec.Tolerations = append(podSpec.Tolerations, toleration)
+		}
+	}
+}
+
+// SetPodPriorityClass sets the priority class of the pod, if given.
+func SetPodPriorityClass(podSpec *corev1.PodSpec, priorityClassName string) {
+	if podSpec == nil || priorityClassName == "" {
+		return
+	}
+	podSpec.PriorityClassName = priorityClassName
+	// the admission controller fills the value from the class
+	podSpec.Priority = nil
+}
@@ -0,0 +1,192 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetPodNodeSelector(t *testing.T) {
+	type testCase struct {
+		name         string
+		podSpec      *corev1.PodSpec
+		selector     *metav1.LabelSelector
+		expectedYAML string
+	}
+
+	testCases := []testCase{
+		{
+			name:         "nil selector",
+			podSpec:      &corev1.PodSpec{},
+			expectedYAML: "containers: null\n",
+		},
+		{
+			name:    "labels only",
+			podSpec: &corev1.PodSpec{},
+			selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+			},
+			expectedYAML: podSpecOnlyNodeSelector,
+		},
+		{
+			name:    "labels and expressions",
+			podSpec: &corev1.PodSpec{},
+			selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "topology.kubernetes.io/zone",
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   []string{"zone-a", "zone-b"},
+					},
+				},
+			},
+			expectedYAML: podSpecNodeSelectorAndAffinity,
+		},
+		{
+			name: "expressions merged with existing affinity",
+			podSpec: &corev1.PodSpec{
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{
+								{
+									MatchExpressions: []corev1.NodeSelectorRequirement{
+										{
+											Key:      "kubernetes.io/arch",
+											Operator: corev1.NodeSelectorOpIn,
+											Values:   []string{"amd64"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "node-role.kubernetes.io/infra",
+						Operator: metav1.LabelSelectorOpDoesNotExist,
+					},
+				},
+			},
+			expectedYAML: podSpecMergedAffinity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.podSpec.DeepCopy()
+			SetPodNodeSelector(got, tc.selector)
+			data, err := yaml.Marshal(got)
+			if err != nil {
+				t.Errorf("error marshalling yaml: %v", err)
+			}
+			gotYAML := string(data)
+			if gotYAML != tc.expectedYAML {
+				t.Errorf("output mismatch:\ngot=%v\nexpected=%v\n", gotYAML, tc.expectedYAML)
+			}
+		})
+	}
+}
+
+func TestSetPodTolerations(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		Tolerations: []corev1.Toleration{
+			{
+				Key:      "foo",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			},
+		},
+	}
+	SetPodTolerations(podSpec, []corev1.Toleration{
+		{
+			Key:      "foo",
+			Operator: corev1.TolerationOpEqual,
+			Value:    "bar",
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+		{
+			Key:      "foo",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		},
+	})
+	if len(podSpec.Tolerations) != 2 {
+		t.Fatalf("unexpected tolerations: %v", podSpec.Tolerations)
+	}
+	if podSpec.Tolerations[0].Value != "bar" {
+		t.Errorf("toleration not replaced: %v", podSpec.Tolerations[0])
+	}
+	if podSpec.Tolerations[1].Effect != corev1.TaintEffectNoExecute {
+		t.Errorf("toleration not added: %v", podSpec.Tolerations[1])
+	}
+}
+
+const podSpecOnlyNodeSelector = `containers: null
+nodeSelector:
+  node-role.kubernetes.io/worker: ""
+`
+
+const podSpecNodeSelectorAndAffinity = `affinity:
+  nodeAffinity:
+    requiredDuringSchedulingIgnoredDuringExecution:
+      nodeSelectorTerms:
+      - matchExpressions:
+        - key: topology.kubernetes.io/zone
+          operator: In
+          values:
+          - zone-a
+          - zone-b
+containers: null
+nodeSelector:
+  node-role.kubernetes.io/worker: ""
+`
+
+const podSpecMergedAffinity = `affinity:
+  nodeAffinity:
+    requiredDuringSchedulingIgnoredDuringExecution:
+      nodeSelectorTerms:
+      - matchExpressions:
+        - key: kubernetes.io/arch
+          operator: In
+          values:
+          - amd64
+        - key: node-role.kubernetes.io/infra
+          operator: DoesNotExist
+containers: null
+`
+
+func TestSetPodPriorityClass(t *testing.T) {
+	podSpec := corev1.PodSpec{PriorityClassName: "keep"}
+	SetPodPriorityClass(&podSpec, "")
+	if podSpec.PriorityClassName != "keep" {
+		t.Errorf("empty name should not change the priority class, got %q", podSpec.PriorityClassName)
+	}
+	SetPodPriorityClass(&podSpec, "prio")
+	if podSpec.PriorityClassName != "prio" {
+		t.Errorf("priority class %q expected %q", podSpec.PriorityClassName, "prio")
+	}
+}
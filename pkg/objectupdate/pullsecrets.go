@@ -0,0 +1,75 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ImagePullOptions let the pods pull from registries which require credentials
+type ImagePullOptions struct {
+	// Secrets are added to all the ServiceAccounts and pod templates
+	Secrets []string
+	// DockerConfigData, if not empty, is rendered as a Secret in each namespace,
+	// named after the first of Secrets.
+	DockerConfigData []byte
+}
+
+// SecretToCreate returns the name of the Secret to render, if any.
+func (io ImagePullOptions) SecretToCreate() string {
+	if len(io.DockerConfigData) == 0 || len(io.Secrets) == 0 {
+		return ""
+	}
+	return io.Secrets[0]
+}
+
+// SetImagePullSecrets adds the secrets to the ServiceAccounts and pod templates among objs,
+// skipping the ones already referenced.
+func SetImagePullSecrets(objs []client.Object, secrets []string) {
+	if len(secrets) == 0 {
+		return
+	}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *corev1.ServiceAccount:
+			o.ImagePullSecrets = addPullSecrets(o.ImagePullSecrets, secrets)
+		case *appsv1.Deployment:
+			o.Spec.Template.Spec.ImagePullSecrets = addPullSecrets(o.Spec.Template.Spec.ImagePullSecrets, secrets)
+		case *appsv1.DaemonSet:
+			o.Spec.Template.Spec.ImagePullSecrets = addPullSecrets(o.Spec.Template.Spec.ImagePullSecrets, secrets)
+		}
+	}
+}
+
+func addPullSecrets(refs []corev1.LocalObjectReference, secrets []string) []corev1.LocalObjectReference {
+	for _, secret := range secrets {
+		found := false
+		for _, ref := range refs {
+			if ref.Name == secret {
+				found = true
+				break
+			}
+		}
+		if !found {
+			refs = append(refs, corev1.LocalObjectReference{Name: secret})
+		}
+	}
+	return refs
+}
@@ -0,0 +1,75 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSetImagePullSecrets(t *testing.T) {
+	type testCase struct {
+		name     string
+		existing []corev1.LocalObjectReference
+		secrets  []string
+		expected []corev1.LocalObjectReference
+	}
+
+	testCases := []testCase{
+		{
+			name: "no secrets",
+		},
+		{
+			name:     "add secrets",
+			secrets:  []string{"foo", "bar"},
+			expected: []corev1.LocalObjectReference{{Name: "foo"}, {Name: "bar"}},
+		},
+		{
+			name:     "skip existing",
+			existing: []corev1.LocalObjectReference{{Name: "bar"}},
+			secrets:  []string{"foo", "bar"},
+			expected: []corev1.LocalObjectReference{{Name: "bar"}, {Name: "foo"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &corev1.ServiceAccount{
+				ImagePullSecrets: append([]corev1.LocalObjectReference{}, tc.existing...),
+			}
+			dp := &appsv1.Deployment{}
+			dp.Spec.Template.Spec.ImagePullSecrets = append([]corev1.LocalObjectReference{}, tc.existing...)
+			ds := &appsv1.DaemonSet{}
+			ds.Spec.Template.Spec.ImagePullSecrets = append([]corev1.LocalObjectReference{}, tc.existing...)
+			cm := &corev1.ConfigMap{}
+
+			SetImagePullSecrets([]client.Object{sa, dp, ds, cm}, tc.secrets)
+
+			expected := append([]corev1.LocalObjectReference{}, tc.expected...)
+			for _, got := range [][]corev1.LocalObjectReference{sa.ImagePullSecrets, dp.Spec.Template.Spec.ImagePullSecrets, ds.Spec.Template.Spec.ImagePullSecrets} {
+				if !reflect.DeepEqual(got, expected) {
+					t.Errorf("pull secrets %v expected %v", got, expected)
+				}
+			}
+		})
+	}
+}
@@ -17,6 +17,8 @@
 package rbac
 
 import (
+	"reflect"
+
 	rbacv1 "k8s.io/api/rbac/v1"
 )
 
@@ -37,3 +39,34 @@ func ClusterRoleBinding(crb *rbacv1.ClusterRoleBinding, serviceAccount, namespac
 		crb.Subjects[idx].Namespace = namespace
 	}
 }
+
+// ClusterRoleLeases allows to run the leader election on the given leases, or on any lease if none is given.
+func ClusterRoleLeases(cr *rbacv1.ClusterRole, leaseNames ...string) {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"coordination.k8s.io"},
+			Resources: []string{"leases"},
+			Verbs:     []string{"create"},
+		},
+		{
+			APIGroups:     []string{"coordination.k8s.io"},
+			Resources:     []string{"leases"},
+			ResourceNames: leaseNames,
+			Verbs:         []string{"get", "update"},
+		},
+	}
+	for _, rule := range rules {
+		if !hasRule(cr.Rules, rule) {
+			cr.Rules = append(cr.Rules, rule)
+		}
+	}
+}
+
+func hasRule(rules []rbacv1.PolicyRule, rule rbacv1.PolicyRule) bool {
+	for _, cur := range rules {
+		if reflect.DeepEqual(cur, rule) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+type ResourcesOptions struct {
+	// Containers maps the container names to their resources, which are merged
+	// into the existing ones. Unlisted containers are left untouched.
+	Containers map[string]corev1.ResourceRequirements
+	// Guaranteed makes the pods Guaranteed QoS: every container gets the same
+	// cpu and memory requests and limits, filling the missing side from the other.
+	Guaranteed bool
+}
+
+// SetPodResources applies the resources options to all the containers of the pod.
+func SetPodResources(podSpec *corev1.PodSpec, opts ResourcesOptions) error {
+	for _, cnts := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for idx := range cnts {
+			cnt := &cnts[idx]
+			if res, ok := opts.Containers[cnt.Name]; ok {
+				cnt.Resources.Requests = mergeResourceList(cnt.Resources.Requests, res.Requests)
+				cnt.Resources.Limits = mergeResourceList(cnt.Resources.Limits, res.Limits)
+			}
+			if !opts.Guaranteed {
+				continue
+			}
+			if err := makeGuaranteed(cnt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func makeGuaranteed(cnt *corev1.Container) error {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		req, hasReq := cnt.Resources.Requests[name]
+		lim, hasLim := cnt.Resources.Limits[name]
+		switch {
+		case hasReq && hasLim:
+			if req.Cmp(lim) != 0 {
+				return fmt.Errorf("container %q: %s request %s differs from limit %s, cannot be Guaranteed QoS", cnt.Name, name, req.String(), lim.String())
+			}
+		case hasReq:
+			cnt.Resources.Limits = mergeResourceList(cnt.Resources.Limits, corev1.ResourceList{name: req})
+		case hasLim:
+			cnt.Resources.Requests = mergeResourceList(cnt.Resources.Requests, corev1.ResourceList{name: lim})
+		default:
+			return fmt.Errorf("container %q: missing %s resources, cannot be Guaranteed QoS", cnt.Name, name)
+		}
+	}
+	return nil
+}
+
+func mergeResourceList(dst, src corev1.ResourceList) corev1.ResourceList {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(corev1.ResourceList, len(src))
+	}
+	for name, val := range src {
+		dst[name] = val.DeepCopy()
+	}
+	return dst
+}
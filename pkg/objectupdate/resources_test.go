@@ -0,0 +1,142 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package objectupdate
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestSetPodResources(t *testing.T) {
+	type testCase struct {
+		name        string
+		resources   corev1.ResourceRequirements
+		opts        ResourcesOptions
+		expected    corev1.ResourceRequirements
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "untouched",
+		},
+		{
+			name: "override requests",
+			opts: ResourcesOptions{
+				Containers: map[string]corev1.ResourceRequirements{
+					"foo": {Requests: newResourceList("100m", "100Mi")},
+				},
+			},
+			expected: corev1.ResourceRequirements{Requests: newResourceList("100m", "100Mi")},
+		},
+		{
+			name:      "guaranteed from requests",
+			resources: corev1.ResourceRequirements{Requests: newResourceList("100m", "100Mi")},
+			opts: ResourcesOptions{
+				Guaranteed: true,
+			},
+			expected: corev1.ResourceRequirements{
+				Requests: newResourceList("100m", "100Mi"),
+				Limits:   newResourceList("100m", "100Mi"),
+			},
+		},
+		{
+			name:      "guaranteed mixing requests and limits",
+			resources: corev1.ResourceRequirements{Requests: newResourceList("100m", "")},
+			opts: ResourcesOptions{
+				Containers: map[string]corev1.ResourceRequirements{
+					"foo": {Limits: newResourceList("", "100Mi")},
+				},
+				Guaranteed: true,
+			},
+			expected: corev1.ResourceRequirements{
+				Requests: newResourceList("100m", "100Mi"),
+				Limits:   newResourceList("100m", "100Mi"),
+			},
+		},
+		{
+			name: "guaranteed without resources",
+			opts: ResourcesOptions{
+				Guaranteed: true,
+			},
+			expectError: true,
+		},
+		{
+			name: "guaranteed with different requests and limits",
+			resources: corev1.ResourceRequirements{
+				Requests: newResourceList("100m", "100Mi"),
+				Limits:   newResourceList("200m", "100Mi"),
+			},
+			opts: ResourcesOptions{
+				Guaranteed: true,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "foo", Resources: tc.resources},
+					},
+				},
+			}
+			err := SetPodResources(&pod.Spec, tc.opts)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %v", pod.Spec.Containers[0].Resources)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+			got := pod.Spec.Containers[0].Resources
+			if !equalResourceLists(got.Requests, tc.expected.Requests) || !equalResourceLists(got.Limits, tc.expected.Limits) {
+				t.Errorf("resources %v expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func newResourceList(cpu, memory string) corev1.ResourceList {
+	ret := corev1.ResourceList{}
+	if cpu != "" {
+		ret[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		ret[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return ret
+}
+
+func equalResourceLists(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, qty := range a {
+		other, ok := b[name]
+		if !ok || qty.Cmp(other) != 0 {
+			return false
+		}
+	}
+	return true
+}
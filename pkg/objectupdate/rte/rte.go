@@ -27,7 +27,6 @@ import (
 	selinuxassets "github.com/k8stopologyawareschedwg/deployer/pkg/assets/selinux"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/deployer/platform"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/flagcodec"
-	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 )
@@ -71,8 +70,9 @@ func ContainerConfig(podSpec *corev1.PodSpec, cnt *corev1.Container, configMapNa
 
 func DaemonSet(ds *appsv1.DaemonSet, plat platform.Platform, configMapName string, opts objectupdate.DaemonSetOptions) {
 	podSpec := &ds.Spec.Template.Spec
+	imgs := opts.Images.WithDefaults()
 	if cntSpec := objectupdate.FindContainerByName(ds.Spec.Template.Spec.Containers, manifests.ContainerNameRTE); cntSpec != nil {
-		cntSpec.Image = images.ResourceTopologyExporterImage
+		cntSpec.Image = imgs.ResourceTopologyExporter
 
 		cntSpec.ImagePullPolicy = corev1.PullAlways
 		if opts.PullIfNotPresent {
@@ -144,9 +144,13 @@ func DaemonSet(ds *appsv1.DaemonSet, plat platform.Platform, configMapName strin
 		ds.Spec.Template.Spec.Volumes = append(ds.Spec.Template.Spec.Volumes, rtePodVolumes...)
 	}
 
-	if opts.NodeSelector != nil {
-		podSpec.NodeSelector = opts.NodeSelector.MatchLabels
+	if cntSpec := objectupdate.FindContainerByName(podSpec.Containers, manifests.ContainerNameRTESharedPool); cntSpec != nil {
+		cntSpec.Image = imgs.Pause
 	}
+
+	objectupdate.SetPodNodeSelector(podSpec, opts.NodeSelector)
+	objectupdate.SetPodTolerations(podSpec, opts.Tolerations)
+	objectupdate.SetPodPriorityClass(podSpec, opts.PriorityClassName)
 	MetricsPort(ds, metricsPort)
 }
 
@@ -19,9 +19,11 @@ package sched
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/yaml"
@@ -48,6 +50,346 @@ func SchedulerConfig(cm *corev1.ConfigMap, schedulerName string, params *manifes
 	return nil
 }
 
+// SchedulerConfigProfiles replaces the profile templateName with the given profiles. See RenderProfiles.
+func SchedulerConfigProfiles(cm *corev1.ConfigMap, templateName string, profiles []manifests.ConfigParams) error {
+	if cm.Data == nil {
+		return fmt.Errorf("no data found in ConfigMap: %s/%s", cm.Namespace, cm.Name)
+	}
+
+	data, ok := cm.Data[manifests.SchedulerConfigFileName]
+	if !ok {
+		return fmt.Errorf("no data key named: %s found in ConfigMap: %s/%s", manifests.SchedulerConfigFileName, cm.Namespace, cm.Name)
+	}
+
+	newData, err := RenderProfiles([]byte(data), templateName, profiles)
+	if err != nil {
+		return err
+	}
+
+	cm.Data[manifests.SchedulerConfigFileName] = string(newData)
+	return nil
+}
+
+// RenderProfiles replaces the profile templateName with a copy of it for each of the given
+// profiles, named after the profile and with the plugin args updated. The other profiles are kept.
+func RenderProfiles(data []byte, templateName string, profiles []manifests.ConfigParams) ([]byte, error) {
+	var r unstructured.Unstructured
+	if err := yaml.Unmarshal(data, &r.Object); err != nil {
+		return data, fmt.Errorf("cannot unmarshal scheduler config: %w", err)
+	}
+
+	curProfiles, ok, err := unstructured.NestedSlice(r.Object, "profiles")
+	if !ok || err != nil {
+		return data, fmt.Errorf("cannot find the scheduler profiles: %v", err)
+	}
+
+	found := false
+	newProfiles := make([]interface{}, 0, len(curProfiles)+len(profiles))
+	for _, prof := range curProfiles {
+		profile, ok := prof.(map[string]interface{})
+		if !ok {
+			return data, fmt.Errorf("unexpected profile data")
+		}
+		profileName, _, err := unstructured.NestedString(profile, "schedulerName")
+		if err != nil {
+			return data, fmt.Errorf("cannot get profile name: %w", err)
+		}
+		if profileName != templateName {
+			newProfiles = append(newProfiles, profile)
+			continue
+		}
+		found = true
+
+		for idx := range profiles {
+			params := &profiles[idx]
+			newProfile := runtime.DeepCopyJSON(profile)
+			if err := updateProfile(newProfile, params); err != nil {
+				return data, fmt.Errorf("cannot render profile %q: %w", params.ProfileName, err)
+			}
+			newProfiles = append(newProfiles, newProfile)
+		}
+	}
+	if !found {
+		return data, fmt.Errorf("cannot find the template profile %q", templateName)
+	}
+
+	if err := unstructured.SetNestedSlice(r.Object, newProfiles, "profiles"); err != nil {
+		return data, err
+	}
+	return yaml.Marshal(&r.Object)
+}
+
+func updateProfile(profile map[string]interface{}, params *manifests.ConfigParams) error {
+	if err := unstructured.SetNestedField(profile, params.ProfileName, "schedulerName"); err != nil {
+		return err
+	}
+	pluginConfigs, _, err := unstructured.NestedSlice(profile, "pluginConfig")
+	if err != nil {
+		return err
+	}
+	for _, plConf := range pluginConfigs {
+		pluginConf, ok := plConf.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected plugin config data")
+		}
+		name, _, err := unstructured.NestedString(pluginConf, "name")
+		if err != nil {
+			return err
+		}
+		if name != manifests.SchedulerPluginName {
+			continue
+		}
+		args, _, err := unstructured.NestedMap(pluginConf, "args")
+		if err != nil {
+			return err
+		}
+		if args == nil {
+			args = make(map[string]interface{})
+		}
+		if _, err := updateArgs(args, params); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(pluginConf, args, "args"); err != nil {
+			return err
+		}
+	}
+	return unstructured.SetNestedSlice(profile, pluginConfigs, "pluginConfig")
+}
+
+// SchedulerConfigLeaderElection enables the leader election. See RenderLeaderElection.
+func SchedulerConfigLeaderElection(cm *corev1.ConfigMap, leaseName, leaseNamespace string) error {
+	if cm.Data == nil {
+		return fmt.Errorf("no data found in ConfigMap: %s/%s", cm.Namespace, cm.Name)
+	}
+
+	data, ok := cm.Data[manifests.SchedulerConfigFileName]
+	if !ok {
+		return fmt.Errorf("no data key named: %s found in ConfigMap: %s/%s", manifests.SchedulerConfigFileName, cm.Namespace, cm.Name)
+	}
+
+	newData, err := RenderLeaderElection([]byte(data), leaseName, leaseNamespace)
+	if err != nil {
+		return err
+	}
+
+	cm.Data[manifests.SchedulerConfigFileName] = string(newData)
+	return nil
+}
+
+// RenderLeaderElection enables the leader election on the given lease. The lease must not be
+// the one of the default scheduler (kube-system/kube-scheduler), or the two would compete.
+func RenderLeaderElection(data []byte, leaseName, leaseNamespace string) ([]byte, error) {
+	if leaseName == "" || leaseNamespace == "" {
+		return data, fmt.Errorf("leader election requires the lease name and namespace")
+	}
+	if leaseName == "kube-scheduler" && leaseNamespace == "kube-system" {
+		return data, fmt.Errorf("cannot use the lease of the default scheduler: %s/%s", leaseNamespace, leaseName)
+	}
+
+	var r unstructured.Unstructured
+	if err := yaml.Unmarshal(data, &r.Object); err != nil {
+		return data, fmt.Errorf("cannot unmarshal scheduler config: %w", err)
+	}
+	leaderElection := map[string]interface{}{
+		"leaderElect":       true,
+		"resourceLock":      "leases",
+		"resourceName":      leaseName,
+		"resourceNamespace": leaseNamespace,
+	}
+	if err := unstructured.SetNestedMap(r.Object, leaderElection, "leaderElection"); err != nil {
+		return data, err
+	}
+	return yaml.Marshal(&r.Object)
+}
+
+// SchedulerConfigAPIVersion converts the configuration to the given API version. See RenderConfigAPIVersion.
+func SchedulerConfigAPIVersion(cm *corev1.ConfigMap, apiVersion string) error {
+	if cm.Data == nil {
+		return fmt.Errorf("no data found in ConfigMap: %s/%s", cm.Namespace, cm.Name)
+	}
+
+	data, ok := cm.Data[manifests.SchedulerConfigFileName]
+	if !ok {
+		return fmt.Errorf("no data key named: %s found in ConfigMap: %s/%s", manifests.SchedulerConfigFileName, cm.Namespace, cm.Name)
+	}
+
+	newData, err := RenderConfigAPIVersion([]byte(data), apiVersion)
+	if err != nil {
+		return err
+	}
+
+	cm.Data[manifests.SchedulerConfigFileName] = string(newData)
+	return nil
+}
+
+// RenderConfigAPIVersion converts the configuration to the given KubeSchedulerConfiguration API version,
+// including the plugin args which declare their own. The multiPoint extension point can't be converted
+// to v1beta2, which lacks it. An empty apiVersion keeps the configuration as it is.
+func RenderConfigAPIVersion(data []byte, apiVersion string) ([]byte, error) {
+	if apiVersion == "" {
+		return data, nil
+	}
+
+	var r unstructured.Unstructured
+	if err := yaml.Unmarshal(data, &r.Object); err != nil {
+		return data, fmt.Errorf("cannot unmarshal scheduler config: %w", err)
+	}
+	if r.GetAPIVersion() == apiVersion {
+		return data, nil
+	}
+	r.SetAPIVersion(apiVersion)
+
+	profiles, _, err := unstructured.NestedSlice(r.Object, "profiles")
+	if err != nil {
+		return data, fmt.Errorf("cannot find the scheduler profiles: %w", err)
+	}
+	for _, prof := range profiles {
+		profile, ok := prof.(map[string]interface{})
+		if !ok {
+			return data, fmt.Errorf("unexpected profile data")
+		}
+		if _, ok, _ := unstructured.NestedFieldNoCopy(profile, "plugins", "multiPoint"); ok && apiVersion == manifests.SchedulerConfigAPIVersionV1beta2 {
+			return data, fmt.Errorf("cannot convert the multiPoint plugins to %s", apiVersion)
+		}
+
+		pluginConfigs, _, err := unstructured.NestedSlice(profile, "pluginConfig")
+		if err != nil {
+			return data, err
+		}
+		for _, plConf := range pluginConfigs {
+			pluginConf, ok := plConf.(map[string]interface{})
+			if !ok {
+				return data, fmt.Errorf("unexpected plugin config data")
+			}
+			argsVersion, ok, _ := unstructured.NestedString(pluginConf, "args", "apiVersion")
+			if !ok || !strings.HasPrefix(argsVersion, manifests.SchedulerConfigGroup+"/") {
+				continue // plugin specific, or implicit
+			}
+			if err := unstructured.SetNestedField(pluginConf, apiVersion, "args", "apiVersion"); err != nil {
+				return data, err
+			}
+		}
+		if len(pluginConfigs) > 0 {
+			if err := unstructured.SetNestedSlice(profile, pluginConfigs, "pluginConfig"); err != nil {
+				return data, err
+			}
+		}
+	}
+	if len(profiles) > 0 {
+		if err := unstructured.SetNestedSlice(r.Object, profiles, "profiles"); err != nil {
+			return data, err
+		}
+	}
+	return yaml.Marshal(&r.Object)
+}
+
+// SchedulerConfigMergeProfiles merges the profiles of the rendered configuration into the
+// existing scheduler configuration stored in the given key of the ConfigMap.
+func SchedulerConfigMergeProfiles(cm *corev1.ConfigMap, key string, rendered []byte) error {
+	if cm.Data == nil {
+		return fmt.Errorf("no data found in ConfigMap: %s/%s", cm.Namespace, cm.Name)
+	}
+
+	data, ok := cm.Data[key]
+	if !ok {
+		return fmt.Errorf("no data key named: %s found in ConfigMap: %s/%s", key, cm.Namespace, cm.Name)
+	}
+
+	newData, err := MergeConfigProfiles([]byte(data), rendered)
+	if err != nil {
+		return err
+	}
+
+	cm.Data[key] = string(newData)
+	return nil
+}
+
+// MergeConfigProfiles adds the profiles of the rendered configuration using our plugin to the existing
+// configuration, converted to its API version. Existing profiles with the same name are replaced, all
+// the other settings of the existing configuration are kept.
+func MergeConfigProfiles(existing, rendered []byte) ([]byte, error) {
+	var cur unstructured.Unstructured
+	if err := yaml.Unmarshal(existing, &cur.Object); err != nil {
+		return existing, fmt.Errorf("cannot unmarshal the existing scheduler config: %w", err)
+	}
+	if cur.GetKind() != "KubeSchedulerConfiguration" || !strings.HasPrefix(cur.GetAPIVersion(), manifests.SchedulerConfigGroup+"/") {
+		return existing, fmt.Errorf("unexpected existing scheduler config: kind %q apiVersion %q", cur.GetKind(), cur.GetAPIVersion())
+	}
+
+	converted, err := RenderConfigAPIVersion(rendered, cur.GetAPIVersion())
+	if err != nil {
+		return existing, err
+	}
+	var r unstructured.Unstructured
+	if err := yaml.Unmarshal(converted, &r.Object); err != nil {
+		return existing, fmt.Errorf("cannot unmarshal the rendered scheduler config: %w", err)
+	}
+
+	renderedProfiles, _, err := unstructured.NestedSlice(r.Object, "profiles")
+	if err != nil {
+		return existing, err
+	}
+	profileParams, err := manifests.DecodeSchedulerProfilesFromData(converted)
+	if err != nil {
+		return existing, err
+	}
+	extraProfiles := make(map[string]interface{})
+	var extraNames []string
+	for _, prof := range renderedProfiles {
+		profile, ok := prof.(map[string]interface{})
+		if !ok {
+			return existing, fmt.Errorf("unexpected profile data")
+		}
+		name, _, _ := unstructured.NestedString(profile, "schedulerName")
+		if manifests.FindSchedulerProfileByName(profileParams, name) == nil {
+			continue // not using our plugin
+		}
+		extraProfiles[name] = profile
+		extraNames = append(extraNames, name)
+	}
+	if len(extraNames) == 0 {
+		return existing, fmt.Errorf("no profiles using %s rendered", manifests.SchedulerPluginName)
+	}
+
+	curProfiles, _, err := unstructured.NestedSlice(cur.Object, "profiles")
+	if err != nil {
+		return existing, err
+	}
+	if len(curProfiles) == 0 {
+		// an implicit default-scheduler profile is in place only if no profile is given
+		curProfiles = append(curProfiles, map[string]interface{}{
+			"schedulerName": corev1.DefaultSchedulerName,
+		})
+	}
+	newProfiles := make([]interface{}, 0, len(curProfiles)+len(extraNames))
+	for _, prof := range curProfiles {
+		profile, ok := prof.(map[string]interface{})
+		if !ok {
+			return existing, fmt.Errorf("unexpected existing profile data")
+		}
+		name, _, _ := unstructured.NestedString(profile, "schedulerName")
+		if name == "" {
+			name = corev1.DefaultSchedulerName
+		}
+		if extra, ok := extraProfiles[name]; ok {
+			newProfiles = append(newProfiles, extra) // replace in place
+			delete(extraProfiles, name)
+			continue
+		}
+		newProfiles = append(newProfiles, profile)
+	}
+	for _, name := range extraNames {
+		if extra, ok := extraProfiles[name]; ok {
+			newProfiles = append(newProfiles, extra)
+		}
+	}
+
+	if err := unstructured.SetNestedSlice(cur.Object, newProfiles, "profiles"); err != nil {
+		return existing, err
+	}
+	return yaml.Marshal(&cur.Object)
+}
+
 func RenderConfig(data []byte, schedulerName string, params *manifests.ConfigParams) ([]byte, bool, error) {
 	if schedulerName == "" || params == nil {
 		klog.V(2).InfoS("missing parameters, passing through", "schedulerName", schedulerName, "params", toJSON(params))
@@ -162,10 +504,64 @@ func updateArgs(args map[string]interface{}, params *manifests.ConfigParams) (bo
 			}
 			updated++
 		}
+		cacheUpdated, err := updateCacheArgs(args, params.Cache)
+		if err != nil {
+			return updated > 0, err
+		}
+		if cacheUpdated {
+			updated++
+		}
+	}
+	if params.ScoringStrategy != nil {
+		err = unstructured.SetNestedMap(args, scoringStrategyArgs(params.ScoringStrategy), "scoringStrategy")
+		if err != nil {
+			return updated > 0, err
+		}
+		updated++
 	}
 	return updated > 0, ensureBackwardCompatibility(args)
 }
 
+func updateCacheArgs(args map[string]interface{}, cp *manifests.ConfigCacheParams) (bool, error) {
+	fields := []struct {
+		name string
+		val  *string
+	}{
+		{name: "resyncMethod", val: cp.ResyncMethod},
+		{name: "foreignPodsDetect", val: cp.ForeignPodsDetectMode},
+		{name: "informerMode", val: cp.InformerMode},
+	}
+	updated := false
+	for _, field := range fields {
+		if field.val == nil {
+			continue // omit when unset, for backward compatibility
+		}
+		if err := unstructured.SetNestedField(args, *field.val, "cache", field.name); err != nil {
+			return updated, err
+		}
+		updated = true
+	}
+	return updated, nil
+}
+
+func scoringStrategyArgs(sp *manifests.ScoringStrategyParams) map[string]interface{} {
+	ret := map[string]interface{}{
+		"type": sp.Type,
+	}
+	if len(sp.Resources) == 0 {
+		return ret // let the plugin use its defaults
+	}
+	resources := make([]interface{}, 0, len(sp.Resources))
+	for _, res := range sp.Resources {
+		resources = append(resources, map[string]interface{}{
+			"name":   res.Name,
+			"weight": res.Weight,
+		})
+	}
+	ret["resources"] = resources
+	return ret
+}
+
 func ensureBackwardCompatibility(args map[string]interface{}) error {
 	resyncPeriod, ok, err := unstructured.NestedInt64(args, "cacheResyncPeriodSeconds")
 	if !ok {
@@ -210,6 +210,132 @@ profiles:
       enabled:
       - name: NodeResourceTopologyMatch
   schedulerName: test-sched-name
+`,
+			expectedUpdate: true,
+		},
+		{
+			name: "cache params",
+			params: &manifests.ConfigParams{
+				Cache: &manifests.ConfigCacheParams{
+					ResyncPeriodSeconds:   newInt64(5),
+					ResyncMethod:          newString(manifests.CacheResyncOnlyExclusiveResources),
+					ForeignPodsDetectMode: newString(manifests.ForeignPodsDetectOnlyExclusiveResources),
+					InformerMode:          newString(manifests.CacheInformerDedicated),
+				},
+			},
+			initial: configTemplateEmpty,
+			expected: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- pluginConfig:
+  - args:
+      cache:
+        foreignPodsDetect: OnlyExclusiveResources
+        informerMode: Dedicated
+        resyncMethod: OnlyExclusiveResources
+      cacheResyncPeriodSeconds: 5
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+`,
+			expectedUpdate: true,
+		},
+		{
+			name: "cache params unset are omitted",
+			params: &manifests.ConfigParams{
+				Cache: &manifests.ConfigCacheParams{
+					ResyncPeriodSeconds: newInt64(5),
+				},
+			},
+			initial:        configTemplateAllValues,
+			expected:       configTemplateAllValues,
+			expectedUpdate: true,
+		},
+		{
+			name: "scoring strategy type only",
+			params: &manifests.ConfigParams{
+				ScoringStrategy: &manifests.ScoringStrategyParams{
+					Type: manifests.ScoringStrategyLeastNUMANodes,
+				},
+			},
+			initial: configTemplateEmpty,
+			expected: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- pluginConfig:
+  - args:
+      scoringStrategy:
+        type: LeastNUMANodes
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+`,
+			expectedUpdate: true,
+		},
+		{
+			name: "scoring strategy with resources and resync",
+			params: &manifests.ConfigParams{
+				Cache: &manifests.ConfigCacheParams{
+					ResyncPeriodSeconds: newInt64(42),
+				},
+				ScoringStrategy: &manifests.ScoringStrategyParams{
+					Type: manifests.ScoringStrategyMostAllocated,
+					Resources: []manifests.ScoringStrategyResourceParams{
+						{Name: "cpu", Weight: 2},
+						{Name: "memory", Weight: 1},
+					},
+				},
+			},
+			initial: configTemplateAllValues,
+			expected: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- pluginConfig:
+  - args:
+      cacheResyncPeriodSeconds: 42
+      scoringStrategy:
+        resources:
+        - name: cpu
+          weight: 2
+        - name: memory
+          weight: 1
+        type: MostAllocated
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
 `,
 			expectedUpdate: true,
 		},
@@ -239,6 +365,309 @@ profiles:
 	}
 }
 
+func TestRenderProfiles(t *testing.T) {
+	profiles := []manifests.ConfigParams{
+		{
+			ProfileName: "single-numa",
+			ScoringStrategy: &manifests.ScoringStrategyParams{
+				Type: manifests.ScoringStrategyLeastNUMANodes,
+			},
+		},
+		{
+			ProfileName: "best-effort",
+			Cache: &manifests.ConfigCacheParams{
+				ResyncPeriodSeconds: newInt64(10),
+			},
+			ScoringStrategy: &manifests.ScoringStrategyParams{
+				Type: manifests.ScoringStrategyLeastAllocated,
+			},
+		},
+	}
+
+	data, err := RenderProfiles([]byte(configTemplateAllValuesMulti), "test-sched-name", profiles)
+	if err != nil {
+		t.Fatalf("RenderProfiles() failed: %v", err)
+	}
+	expected := `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- plugins:
+    filter:
+      disabled:
+      - name: '*'
+      enabled:
+      - name: NodeResourceFit
+  schedulerName: onlyResourceFit
+- pluginConfig:
+  - args:
+      cacheResyncPeriodSeconds: 5
+      scoringStrategy:
+        type: LeastNUMANodes
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: single-numa
+- pluginConfig:
+  - args:
+      cacheResyncPeriodSeconds: 10
+      scoringStrategy:
+        type: LeastAllocated
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: best-effort
+`
+	if string(data) != expected {
+		t.Errorf("rendering failed.\nrendered=[%s]\nexpected=[%s]\n", string(data), expected)
+	}
+
+	decoded, err := manifests.DecodeSchedulerProfilesFromData(data)
+	if err != nil {
+		t.Fatalf("cannot decode the rendered profiles: %v", err)
+	}
+	if len(decoded) != len(profiles) {
+		t.Fatalf("decoded %d profiles expected %d", len(decoded), len(profiles))
+	}
+	for _, prof := range profiles {
+		if manifests.FindSchedulerProfileByName(decoded, prof.ProfileName) == nil {
+			t.Errorf("cannot find profile %q", prof.ProfileName)
+		}
+	}
+
+	if _, err := RenderProfiles([]byte(configTemplateAllValues), "numa-aware-sched", profiles); err == nil {
+		t.Errorf("rendered profiles from a missing template")
+	}
+}
+
+func TestRenderConfigAPIVersion(t *testing.T) {
+	type testCase struct {
+		name        string
+		initial     string
+		apiVersion  string
+		expected    string
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "unset",
+			initial:  configTemplateEmpty,
+			expected: configTemplateEmpty,
+		},
+		{
+			name:       "same version",
+			initial:    configTemplateEmpty,
+			apiVersion: manifests.SchedulerConfigAPIVersionV1beta2,
+			expected:   configTemplateEmpty,
+		},
+		{
+			name: "v1 with explicit args version",
+			initial: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+profiles:
+- pluginConfig:
+  - args:
+      apiVersion: kubescheduler.config.k8s.io/v1beta2
+      kind: NodeResourceTopologyMatchArgs
+    name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+`,
+			apiVersion: manifests.SchedulerConfigAPIVersionV1,
+			expected: `apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+profiles:
+- pluginConfig:
+  - args:
+      apiVersion: kubescheduler.config.k8s.io/v1
+      kind: NodeResourceTopologyMatchArgs
+    name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+`,
+		},
+		{
+			name: "multiPoint to v1beta2",
+			initial: `apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+profiles:
+- plugins:
+    multiPoint:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+`,
+			apiVersion:  manifests.SchedulerConfigAPIVersionV1beta2,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := RenderConfigAPIVersion([]byte(tc.initial), tc.apiVersion)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %s", string(data))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderConfigAPIVersion() failed: %v", err)
+			}
+			if string(data) != tc.expected {
+				t.Errorf("rendering failed.\nrendered=[%s]\nexpected=[%s]\n", string(data), tc.expected)
+			}
+		})
+	}
+}
+
+func TestRenderLeaderElection(t *testing.T) {
+	data, err := RenderLeaderElection([]byte(configTemplateEmpty), "tas", "tas-scheduler")
+	if err != nil {
+		t.Fatalf("RenderLeaderElection() failed: %v", err)
+	}
+	expected := `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: true
+  resourceLock: leases
+  resourceName: tas
+  resourceNamespace: tas-scheduler
+profiles:
+- pluginConfig:
+  - args: {}
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+`
+	if string(data) != expected {
+		t.Errorf("rendering failed.\nrendered=[%s]\nexpected=[%s]\n", string(data), expected)
+	}
+
+	if _, err := RenderLeaderElection([]byte(configTemplateEmpty), "kube-scheduler", "kube-system"); err == nil {
+		t.Errorf("rendered the lease of the default scheduler")
+	}
+}
+
+func TestMergeConfigProfiles(t *testing.T) {
+	type testCase struct {
+		name        string
+		existing    string
+		expected    string
+		expectError bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "implicit default profile",
+			existing: `apiVersion: kubescheduler.config.k8s.io/v1beta3
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: true
+`,
+			expected: `apiVersion: kubescheduler.config.k8s.io/v1beta3
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: true
+profiles:
+- schedulerName: default-scheduler
+- pluginConfig:
+  - args: {}
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+`,
+		},
+		{
+			name: "replace same profile",
+			existing: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+profiles:
+- schedulerName: test-sched-name
+- schedulerName: default-scheduler
+`,
+			expected: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+profiles:
+- pluginConfig:
+  - args: {}
+    name: NodeResourceTopologyMatch
+  plugins:
+    filter:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    reserve:
+      enabled:
+      - name: NodeResourceTopologyMatch
+    score:
+      enabled:
+      - name: NodeResourceTopologyMatch
+  schedulerName: test-sched-name
+- schedulerName: default-scheduler
+`,
+		},
+		{
+			name: "not a scheduler config",
+			existing: `apiVersion: v1
+kind: ConfigMap
+`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := MergeConfigProfiles([]byte(tc.existing), []byte(configTemplateEmpty))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("unexpected success: %s", string(data))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeConfigProfiles() failed: %v", err)
+			}
+			if string(data) != tc.expected {
+				t.Errorf("merging failed.\nrendered=[%s]\nexpected=[%s]\n", string(data), tc.expected)
+			}
+		})
+	}
+}
+
 var configTemplateEmpty string = `apiVersion: kubescheduler.config.k8s.io/v1beta2
 kind: KubeSchedulerConfiguration
 leaderElection:
@@ -343,3 +772,7 @@ profiles:
 func newInt64(value int64) *int64 {
 	return &value
 }
+
+func newString(value string) *string {
+	return &value
+}
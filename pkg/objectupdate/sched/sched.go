@@ -23,14 +23,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/flagcodec"
-	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
 	"github.com/k8stopologyawareschedwg/deployer/pkg/objectupdate"
 )
 
-func SchedulerDeployment(dp *appsv1.Deployment, pullIfNotPresent, ctrlPlaneAffinity bool, verbose int) {
+func SchedulerDeployment(dp *appsv1.Deployment, image string, pullIfNotPresent, ctrlPlaneAffinity bool, verbose int) {
 	cnt := &dp.Spec.Template.Spec.Containers[0] // shortcut
 
-	cnt.Image = images.SchedulerPluginSchedulerImage
+	cnt.Image = image
 	cnt.ImagePullPolicy = pullPolicy(pullIfNotPresent)
 
 	flags := flagcodec.ParseArgvKeyValue(cnt.Args)
@@ -42,8 +41,8 @@ func SchedulerDeployment(dp *appsv1.Deployment, pullIfNotPresent, ctrlPlaneAffin
 	}
 }
 
-func ControllerDeployment(dp *appsv1.Deployment, pullIfNotPresent, ctrlPlaneAffinity bool) {
-	dp.Spec.Template.Spec.Containers[0].Image = images.SchedulerPluginControllerImage
+func ControllerDeployment(dp *appsv1.Deployment, image string, pullIfNotPresent, ctrlPlaneAffinity bool) {
+	dp.Spec.Template.Spec.Containers[0].Image = image
 	dp.Spec.Template.Spec.Containers[0].ImagePullPolicy = pullPolicy(pullIfNotPresent)
 
 	if ctrlPlaneAffinity {
@@ -51,6 +50,20 @@ func ControllerDeployment(dp *appsv1.Deployment, pullIfNotPresent, ctrlPlaneAffi
 	}
 }
 
+// ControllerLeaderElection makes the controller replicas elect a leader.
+func ControllerLeaderElection(dp *appsv1.Deployment) {
+	cnt := &dp.Spec.Template.Spec.Containers[0] // shortcut
+
+	flags := flagcodec.ParseArgvKeyValue(cnt.Args)
+	flags.SetOption("--enableLeaderElection", "true")
+	cnt.Args = flags.Argv()
+}
+
+// SetReplicasAntiAffinity spreads the replicas of the deployment across the nodes.
+func SetReplicasAntiAffinity(dp *appsv1.Deployment) {
+	objectupdate.SetPodAntiAffinity(&dp.Spec.Template.Spec, dp.Spec.Template.Labels)
+}
+
 func pullPolicy(pullIfNotPresent bool) corev1.PullPolicy {
 	if pullIfNotPresent {
 		return corev1.PullIfNotPresent
@@ -22,7 +22,6 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
-	appsv1 "k8s.io/api/apps/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
@@ -65,8 +64,7 @@ func TestSchedulerDeployment(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			dp := dpRef.DeepCopy()
 
-			SchedulerDeployment(dp, tc.pullIfNotPresent, tc.ctrlPlaneAffinity, tc.verbose)
-			fixSchedulerImage(dp)
+			SchedulerDeployment(dp, "test.com/image:latest", tc.pullIfNotPresent, tc.ctrlPlaneAffinity, tc.verbose)
 
 			var sb strings.Builder
 			manifests.RenderObjects([]client.Object{dp}, &sb)
@@ -79,12 +77,6 @@ func TestSchedulerDeployment(t *testing.T) {
 	}
 }
 
-// to make the image (which we change regularly) invariant for the tests
-func fixSchedulerImage(dp *appsv1.Deployment) {
-	cnt := &dp.Spec.Template.Spec.Containers[0] // shortcut
-	cnt.Image = "test.com/image:latest"
-}
-
 const expectedSchedDeploymentDefault string = `---
 apiVersion: apps/v1
 kind: Deployment
@@ -19,7 +19,10 @@ package objectupdate
 import (
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
 )
 
 type DaemonSetOptions struct {
@@ -28,5 +31,10 @@ type DaemonSetOptions struct {
 	PFPEnable          bool
 	NotificationEnable bool
 	NodeSelector       *metav1.LabelSelector
+	Tolerations        []corev1.Toleration
+	PriorityClassName  string
 	UpdateInterval     time.Duration
+	Resources          ResourcesOptions
+	// Images unset are the defaults
+	Images images.ImageSet
 }
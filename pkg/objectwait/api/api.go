@@ -29,7 +29,7 @@ import (
 )
 
 func Creatable(mf apimf.Manifests, cli client.Client, log logr.Logger) []objectwait.WaitableObject {
-	return []objectwait.WaitableObject{
+	objs := []objectwait.WaitableObject{
 		{
 			Obj: mf.Crd,
 			Wait: func(ctx context.Context) error {
@@ -38,15 +38,21 @@ func Creatable(mf apimf.Manifests, cli client.Client, log logr.Logger) []objectw
 			},
 		},
 	}
+	if mf.PriorityClass != nil {
+		objs = append(objs, objectwait.WaitableObject{Obj: mf.PriorityClass})
+	}
+	return objs
 }
 
 func Deletable(mf apimf.Manifests, cli client.Client, log logr.Logger) []objectwait.WaitableObject {
-	return []objectwait.WaitableObject{
-		{
-			Obj: mf.Crd,
-			Wait: func(ctx context.Context) error {
-				return wait.With(cli, log).ForCRDDeleted(ctx, mf.Crd.Name)
-			},
-		},
+	var objs []objectwait.WaitableObject
+	if mf.PriorityClass != nil {
+		objs = append(objs, objectwait.WaitableObject{Obj: mf.PriorityClass})
 	}
+	return append(objs, objectwait.WaitableObject{
+		Obj: mf.Crd,
+		Wait: func(ctx context.Context) error {
+			return wait.With(cli, log).ForCRDDeleted(ctx, mf.Crd.Name)
+		},
+	})
 }
@@ -29,7 +29,11 @@ import (
 )
 
 func Creatable(mf nfdmf.Manifests, cli client.Client, log logr.Logger) []objectwait.WaitableObject {
-	return []objectwait.WaitableObject{
+	var objs []objectwait.WaitableObject
+	if mf.PullSecret != nil {
+		objs = append(objs, objectwait.WaitableObject{Obj: mf.PullSecret})
+	}
+	return append(objs, []objectwait.WaitableObject{
 		{Obj: mf.SATopologyUpdater},
 		{Obj: mf.CRTopologyUpdater},
 		{Obj: mf.CRBTopologyUpdater},
@@ -40,7 +44,7 @@ func Creatable(mf nfdmf.Manifests, cli client.Client, log logr.Logger) []objectw
 				return err
 			},
 		},
-	}
+	}...)
 }
 
 func Deletable(mf nfdmf.Manifests, cli client.Client, log logr.Logger) []objectwait.WaitableObject {
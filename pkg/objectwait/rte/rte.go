@@ -36,6 +36,12 @@ func Creatable(mf rtemf.Manifests, cli client.Client, log logr.Logger) []objectw
 		})
 	}
 
+	if mf.PullSecret != nil {
+		objs = append(objs, objectwait.WaitableObject{
+			Obj: mf.PullSecret,
+		})
+	}
+
 	if mf.SecurityContextConstraint != nil {
 		objs = append(objs, objectwait.WaitableObject{
 			Obj: mf.SecurityContextConstraint,
@@ -43,9 +49,11 @@ func Creatable(mf rtemf.Manifests, cli client.Client, log logr.Logger) []objectw
 	}
 
 	if mf.MachineConfig != nil {
-		// TODO: we should add functionality to wait for the MCP update
 		objs = append(objs, objectwait.WaitableObject{
 			Obj: mf.MachineConfig,
+			Wait: func(ctx context.Context) error {
+				return wait.With(cli, log).ForMachineConfigPools().ForMachineConfigPoolUpdated(ctx, mf.MachineConfig)
+			},
 		})
 	}
 
@@ -87,6 +95,9 @@ func Deletable(mf rtemf.Manifests, cli client.Client, log logr.Logger) []objectw
 	if mf.ConfigMap != nil {
 		objs = append(objs, objectwait.WaitableObject{Obj: mf.ConfigMap})
 	}
+	if mf.PullSecret != nil {
+		objs = append(objs, objectwait.WaitableObject{Obj: mf.PullSecret})
+	}
 	if mf.SecurityContextConstraint != nil {
 		objs = append(objs, objectwait.WaitableObject{
 			Obj: mf.SecurityContextConstraint,
@@ -94,8 +105,10 @@ func Deletable(mf rtemf.Manifests, cli client.Client, log logr.Logger) []objectw
 	}
 	if mf.MachineConfig != nil {
 		objs = append(objs, objectwait.WaitableObject{
-			// TODO: we should add functionality to wait for the MCP update
 			Obj: mf.MachineConfig,
+			Wait: func(ctx context.Context) error {
+				return wait.With(cli, log).ForMachineConfigPools().ForMachineConfigPoolUpdatedAfterDeletion(ctx, mf.MachineConfig)
+			},
 		})
 	}
 	return objs
@@ -29,9 +29,14 @@ import (
 )
 
 func Creatable(mf schedmf.Manifests, cli client.Client, log logr.Logger) []objectwait.WaitableObject {
-	return []objectwait.WaitableObject{
+	objs := []objectwait.WaitableObject{
 		{Obj: mf.Crd},
 		{Obj: mf.Namespace},
+	}
+	if mf.PullSecret != nil {
+		objs = append(objs, objectwait.WaitableObject{Obj: mf.PullSecret})
+	}
+	objs = append(objs, []objectwait.WaitableObject{
 		{Obj: mf.SAScheduler},
 		{Obj: mf.CRScheduler},
 		{Obj: mf.CRBScheduler},
@@ -44,6 +49,11 @@ func Creatable(mf schedmf.Manifests, cli client.Client, log logr.Logger) []objec
 				return err
 			},
 		},
+	}...)
+	if mf.PDBScheduler != nil {
+		objs = append(objs, objectwait.WaitableObject{Obj: mf.PDBScheduler})
+	}
+	return append(objs, []objectwait.WaitableObject{
 		{Obj: mf.SAController},
 		{Obj: mf.CRController},
 		{Obj: mf.CRBController},
@@ -55,7 +65,7 @@ func Creatable(mf schedmf.Manifests, cli client.Client, log logr.Logger) []objec
 				return err
 			},
 		},
-	}
+	}...)
 }
 
 func Deletable(mf schedmf.Manifests, cli client.Client, log logr.Logger) []objectwait.WaitableObject {
@@ -0,0 +1,435 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package olm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/manifests"
+)
+
+const (
+	ManifestsDirName    = "manifests"
+	MetadataDirName     = "metadata"
+	AnnotationsFileName = "annotations.yaml"
+
+	CSVAPIVersion = "operators.coreos.com/v1alpha1"
+	CSVKind       = "ClusterServiceVersion"
+
+	MediaTypeRegistryV1 = "registry+v1"
+)
+
+// Bundle describes the bundle to generate
+type Bundle struct {
+	PackageName    string
+	DisplayName    string
+	Description    string
+	Version        string
+	Channels       []string
+	DefaultChannel string
+	CRDs           []*apiextensionv1.CustomResourceDefinition
+	// Objects are translated in the CSV install strategy when possible,
+	// or added verbatim to the bundle if they are a supported kind.
+	Objects []client.Object
+}
+
+// UnsupportedError reports the objects which OLM cannot install from a bundle, and why
+type UnsupportedError struct {
+	Objects []string
+}
+
+func (ue UnsupportedError) Error() string {
+	return fmt.Sprintf("cannot represent %d object(s) in the OLM bundle: %s", len(ue.Objects), strings.Join(ue.Objects, ", "))
+}
+
+// externalRoleRules are the rules of the well-known roles, outside the bundle, which the bundle
+// service accounts can be granted in other namespaces. OLM installs the namespaced objects only
+// in its install namespace, so these grants become cluster permissions.
+var externalRoleRules = map[string][]rbacv1.PolicyRule{
+	"extension-apiserver-authentication-reader": {
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{"extension-apiserver-authentication"},
+			Verbs:         []string{"get", "list", "watch"},
+		},
+	},
+}
+
+// Write writes the bundle in dir using the registry+v1 format, ready to be fed into opm.
+// Fails with UnsupportedError if any object can't be installed by OLM.
+func Write(dir string, bd Bundle) error {
+	csv, extraObjs, err := bd.ClusterServiceVersion()
+	if err != nil {
+		return err
+	}
+
+	manifestsDir := filepath.Join(dir, ManifestsDirName)
+	metadataDir := filepath.Join(dir, MetadataDirName)
+	for _, d := range []string{manifestsDir, metadataDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := writeObject(filepath.Join(manifestsDir, bd.PackageName+".clusterserviceversion.yaml"), csv); err != nil {
+		return err
+	}
+	for _, crd := range bd.CRDs {
+		fileName := fmt.Sprintf("%s_%s.yaml", crd.Spec.Group, crd.Spec.Names.Plural)
+		if err := writeObject(filepath.Join(manifestsDir, fileName), crd); err != nil {
+			return err
+		}
+	}
+	for _, obj := range extraObjs {
+		gvk, err := apiutil.GVKForObject(obj, k8sscheme.Scheme)
+		if err != nil {
+			return err
+		}
+		fileName := strings.ToLower(fmt.Sprintf("%s_%s_%s.yaml", obj.GetName(), gvk.Version, gvk.Kind))
+		if err := writeObject(filepath.Join(manifestsDir, fileName), obj); err != nil {
+			return err
+		}
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"annotations": bd.Annotations(),
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(metadataDir, AnnotationsFileName), data, 0644)
+}
+
+// Annotations returns the bundle metadata annotations
+func (bd Bundle) Annotations() map[string]string {
+	return map[string]string{
+		"operators.operatorframework.io.bundle.mediatype.v1":       MediaTypeRegistryV1,
+		"operators.operatorframework.io.bundle.manifests.v1":       ManifestsDirName + "/",
+		"operators.operatorframework.io.bundle.metadata.v1":        MetadataDirName + "/",
+		"operators.operatorframework.io.bundle.package.v1":         bd.PackageName,
+		"operators.operatorframework.io.bundle.channels.v1":        strings.Join(bd.Channels, ","),
+		"operators.operatorframework.io.bundle.channel.default.v1": bd.DefaultChannel,
+	}
+}
+
+// ClusterServiceVersion returns the CSV describing the bundle objects and the objects
+// to add verbatim to the bundle. Fails with UnsupportedError if any object can't be installed by OLM.
+func (bd Bundle) ClusterServiceVersion() (*unstructured.Unstructured, []client.Object, error) {
+	var deployments []interface{}
+	var extraObjs []client.Object
+	var unsupported []string
+	namespaces := make(map[string]bool)
+	clusterRoles := make(map[string]*rbacv1.ClusterRole)
+	roles := make(map[string]*rbacv1.Role)
+	var clusterRoleBindings []*rbacv1.ClusterRoleBinding
+	var roleBindings []*rbacv1.RoleBinding
+	var serviceAccounts []string
+
+	for _, obj := range bd.Objects {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			dp, err := csvDeployment(o)
+			if err != nil {
+				return nil, nil, err
+			}
+			deployments = append(deployments, dp)
+		case *rbacv1.ClusterRole:
+			clusterRoles[o.Name] = o
+		case *rbacv1.Role:
+			roles[o.Name] = o
+		case *rbacv1.ClusterRoleBinding:
+			clusterRoleBindings = append(clusterRoleBindings, o)
+		case *rbacv1.RoleBinding:
+			roleBindings = append(roleBindings, o)
+		case *corev1.ServiceAccount:
+			serviceAccounts = append(serviceAccounts, o.Name)
+		case *corev1.ConfigMap:
+			cm := o.DeepCopy()
+			cm.Namespace = "" // OLM installs in the namespace selected by the OperatorGroup
+			extraObjs = append(extraObjs, cm)
+		case *policyv1.PodDisruptionBudget:
+			pdb := o.DeepCopy()
+			pdb.Namespace = ""
+			extraObjs = append(extraObjs, pdb)
+		case *schedulingv1.PriorityClass:
+			extraObjs = append(extraObjs, o)
+		case *corev1.Namespace:
+			// OLM installs in the namespace selected by the OperatorGroup
+			namespaces[o.Name] = true
+		default:
+			unsupported = append(unsupported, describeObject(obj)+" (kind not managed by OLM)")
+		}
+	}
+
+	// OLM binds the CSV permissions to the service accounts in its install namespace. The bindings
+	// to users or groups don't depend on it and are added verbatim, with the roles they grant.
+	// The bindings to service accounts which can't be translated would be bound to a fixed namespace.
+	verbatimClusterRoles := make(map[string]bool)
+	clusterPermissions := make(map[string][]rbacv1.PolicyRule)
+	for _, crb := range clusterRoleBindings {
+		cr, ok := clusterRoles[crb.RoleRef.Name]
+		if !hasServiceAccounts(crb.Subjects) {
+			if ok && !verbatimClusterRoles[cr.Name] {
+				verbatimClusterRoles[cr.Name] = true
+				extraObjs = append(extraObjs, cr)
+			}
+			extraObjs = append(extraObjs, crb)
+			continue
+		}
+		if crb.RoleRef.Kind != "ClusterRole" || !ok || !onlyServiceAccounts(crb.Subjects) {
+			unsupported = append(unsupported, describeObject(crb)+" (binds service accounts to a role not in the bundle)")
+			continue
+		}
+		for _, sub := range crb.Subjects {
+			clusterPermissions[sub.Name] = append(clusterPermissions[sub.Name], cr.Rules...)
+		}
+	}
+	permissions := make(map[string][]rbacv1.PolicyRule)
+	for _, rb := range roleBindings {
+		if rb.RoleRef.Kind != "Role" || !onlyServiceAccounts(rb.Subjects) {
+			unsupported = append(unsupported, describeObject(rb)+" (binds a cluster role or not only service accounts)")
+			continue
+		}
+		if role, ok := roles[rb.RoleRef.Name]; ok && role.Namespace == rb.Namespace {
+			for _, sub := range rb.Subjects {
+				permissions[sub.Name] = append(permissions[sub.Name], role.Rules...)
+			}
+			continue
+		}
+		// grants in other namespaces, e.g. kube-system, can't be installed as they are
+		rules, ok := externalRoleRules[rb.RoleRef.Name]
+		if !ok || namespaces[rb.Namespace] {
+			unsupported = append(unsupported, describeObject(rb)+" (binds a role not in the bundle)")
+			continue
+		}
+		for _, sub := range rb.Subjects {
+			clusterPermissions[sub.Name] = append(clusterPermissions[sub.Name], rules...)
+		}
+	}
+	if len(unsupported) > 0 {
+		return nil, nil, UnsupportedError{Objects: unsupported}
+	}
+	// OLM creates the service accounts listed in the permissions, so we need an entry for each one
+	for _, sa := range serviceAccounts {
+		if _, ok := permissions[sa]; !ok {
+			permissions[sa] = []rbacv1.PolicyRule{}
+		}
+	}
+
+	clusterPermsObj, err := csvPermissions(serviceAccounts, clusterPermissions)
+	if err != nil {
+		return nil, nil, err
+	}
+	permsObj, err := csvPermissions(serviceAccounts, permissions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ownedCRDs []interface{}
+	for _, crd := range bd.CRDs {
+		ownedCRDs = append(ownedCRDs, map[string]interface{}{
+			"name":        crd.Name,
+			"kind":        crd.Spec.Names.Kind,
+			"version":     storageVersion(crd),
+			"displayName": crd.Spec.Names.Kind,
+			"description": crdDescription(crd),
+		})
+	}
+
+	csv := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": CSVAPIVersion,
+			"kind":       CSVKind,
+			"metadata": map[string]interface{}{
+				"name": bd.PackageName + ".v" + bd.Version,
+				"annotations": map[string]interface{}{
+					"alm-examples": "[]",
+					"capabilities": "Basic Install",
+				},
+			},
+			"spec": map[string]interface{}{
+				"displayName": bd.DisplayName,
+				"description": bd.Description,
+				"version":     bd.Version,
+				"maturity":    "alpha",
+				"installModes": []interface{}{
+					installMode("OwnNamespace", true),
+					installMode("SingleNamespace", true),
+					installMode("MultiNamespace", false),
+					installMode("AllNamespaces", false),
+				},
+				"customresourcedefinitions": map[string]interface{}{
+					"owned": ownedCRDs,
+				},
+				"install": map[string]interface{}{
+					"strategy": "deployment",
+					"spec": map[string]interface{}{
+						"clusterPermissions": clusterPermsObj,
+						"permissions":        permsObj,
+						"deployments":        deployments,
+					},
+				},
+			},
+		},
+	}
+	return csv, extraObjs, nil
+}
+
+func csvDeployment(dp *appsv1.Deployment) (map[string]interface{}, error) {
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&dp.Spec)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(spec, "template", "metadata", "creationTimestamp")
+	ret := map[string]interface{}{
+		"name": dp.Name,
+		"spec": spec,
+	}
+	if len(dp.Labels) > 0 {
+		labels := make(map[string]interface{}, len(dp.Labels))
+		for key, val := range dp.Labels {
+			labels[key] = val
+		}
+		ret["label"] = labels
+	}
+	return ret, nil
+}
+
+// csvPermissions returns the permissions in the order the service accounts were found
+func csvPermissions(serviceAccounts []string, perms map[string][]rbacv1.PolicyRule) ([]interface{}, error) {
+	var ret []interface{}
+	done := make(map[string]bool)
+	var names []string
+	names = append(names, serviceAccounts...)
+	names = append(names, sortedKeys(perms)...)
+	for _, sa := range names {
+		rules, ok := perms[sa]
+		if !ok || done[sa] {
+			continue
+		}
+		done[sa] = true
+		var rulesObj []interface{}
+		for idx := range rules {
+			rule, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&rules[idx])
+			if err != nil {
+				return nil, err
+			}
+			rulesObj = append(rulesObj, rule)
+		}
+		if rulesObj == nil {
+			rulesObj = []interface{}{}
+		}
+		ret = append(ret, map[string]interface{}{
+			"serviceAccountName": sa,
+			"rules":              rulesObj,
+		})
+	}
+	if ret == nil {
+		ret = []interface{}{}
+	}
+	return ret, nil
+}
+
+func sortedKeys(perms map[string][]rbacv1.PolicyRule) []string {
+	keys := make([]string, 0, len(perms))
+	for key := range perms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func installMode(modeType string, supported bool) map[string]interface{} {
+	return map[string]interface{}{
+		"type":      modeType,
+		"supported": supported,
+	}
+}
+
+func storageVersion(crd *apiextensionv1.CustomResourceDefinition) string {
+	for _, ver := range crd.Spec.Versions {
+		if ver.Storage {
+			return ver.Name
+		}
+	}
+	return ""
+}
+
+func crdDescription(crd *apiextensionv1.CustomResourceDefinition) string {
+	for _, ver := range crd.Spec.Versions {
+		if ver.Storage && ver.Schema != nil && ver.Schema.OpenAPIV3Schema != nil {
+			return ver.Schema.OpenAPIV3Schema.Description
+		}
+	}
+	return ""
+}
+
+func onlyServiceAccounts(subjects []rbacv1.Subject) bool {
+	for _, sub := range subjects {
+		if sub.Kind != rbacv1.ServiceAccountKind {
+			return false
+		}
+	}
+	return true
+}
+
+func hasServiceAccounts(subjects []rbacv1.Subject) bool {
+	for _, sub := range subjects {
+		if sub.Kind == rbacv1.ServiceAccountKind {
+			return true
+		}
+	}
+	return false
+}
+
+func describeObject(obj client.Object) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if gvk, err := apiutil.GVKForObject(obj, k8sscheme.Scheme); err == nil {
+		kind = gvk.Kind
+	}
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", kind, obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
+
+func writeObject(path string, obj runtime.Object) error {
+	data, err := manifests.SerializeObjectToData(obj)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
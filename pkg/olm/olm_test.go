@@ -0,0 +1,238 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package olm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+func TestClusterServiceVersion(t *testing.T) {
+	type testCase struct {
+		name               string
+		objs               []client.Object
+		expectedDeployment []string
+		expectedClusterSAs []string
+		expectedSAs        []string
+		expectedExtra      []string
+		expectError        bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "deployment with cluster permissions",
+			objs: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "sched"}},
+				&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "sched"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"pods"}}}},
+				newClusterRoleBinding("sched", "sched", "sched"),
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "sched"}},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "sched-config"}},
+			},
+			expectedDeployment: []string{"sched"},
+			expectedClusterSAs: []string{"sched"},
+			expectedSAs:        []string{"sched"},
+			expectedExtra:      []string{"sched-config"},
+		},
+		{
+			name: "unsupported objects",
+			objs: []client.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte"}},
+				&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "rte"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "user bindings added verbatim",
+			objs: []client.Object{
+				&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "nrt-reader"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"noderesourcetopologies"}}}},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "kube-scheduler-nrt"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "nrt-reader"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "system:kube-scheduler"}},
+				},
+			},
+			expectedExtra: []string{"nrt-reader", "kube-scheduler-nrt"},
+		},
+		{
+			name: "kube-system grants as cluster permissions",
+			objs: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "sched"}},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "sched-auth-reader"},
+					RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "extension-apiserver-authentication-reader"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "sched", Namespace: "foo"}},
+				},
+			},
+			expectedClusterSAs: []string{"sched"},
+			expectedSAs:        []string{"sched"},
+		},
+		{
+			name: "service accounts bound to roles not in the bundle",
+			objs: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "sched"}},
+				newClusterRoleBinding("sched-as-kube-scheduler", "system:kube-scheduler", "sched"),
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "sched-leases"},
+					RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "missing"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "sched", Namespace: "foo"}},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "role bindings",
+			objs: []client.Object{
+				&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "ctrl"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"leases"}}}},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "ctrl"},
+					RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "ctrl"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "ctrl", Namespace: "foo"}},
+				},
+			},
+			expectedSAs: []string{"ctrl"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bd := Bundle{PackageName: "test", Version: "0.0.1", Objects: tc.objs}
+			csv, extraObjs, err := bd.ClusterServiceVersion()
+			if tc.expectError {
+				if !errors.As(err, &UnsupportedError{}) {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected failure: %v", err)
+			}
+
+			if got := nestedNames(t, csv, "name", "spec", "install", "spec", "deployments"); !reflect.DeepEqual(got, tc.expectedDeployment) {
+				t.Errorf("deployments mismatch: got %v expected %v", got, tc.expectedDeployment)
+			}
+			if got := nestedNames(t, csv, "serviceAccountName", "spec", "install", "spec", "clusterPermissions"); !reflect.DeepEqual(got, tc.expectedClusterSAs) {
+				t.Errorf("cluster permissions mismatch: got %v expected %v", got, tc.expectedClusterSAs)
+			}
+			if got := nestedNames(t, csv, "serviceAccountName", "spec", "install", "spec", "permissions"); !reflect.DeepEqual(got, tc.expectedSAs) {
+				t.Errorf("permissions mismatch: got %v expected %v", got, tc.expectedSAs)
+			}
+
+			// the objects in the bundle namespaces are installed in the OperatorGroup one
+			var extraNames []string
+			for _, obj := range extraObjs {
+				name := obj.GetName()
+				if obj.GetNamespace() != "" {
+					name = obj.GetNamespace() + "/" + name
+				}
+				extraNames = append(extraNames, name)
+			}
+			if !reflect.DeepEqual(extraNames, tc.expectedExtra) {
+				t.Errorf("extra objects mismatch: got %v expected %v", extraNames, tc.expectedExtra)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	bd := Bundle{
+		PackageName:    "test",
+		Version:        "0.0.1",
+		Channels:       []string{"alpha"},
+		DefaultChannel: "alpha",
+		CRDs: []*apiextensionv1.CustomResourceDefinition{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+				Spec: apiextensionv1.CustomResourceDefinitionSpec{
+					Group: "example.com",
+					Names: apiextensionv1.CustomResourceDefinitionNames{Kind: "Foo", Plural: "foos"},
+					Versions: []apiextensionv1.CustomResourceDefinitionVersion{
+						{Name: "v1", Served: true, Storage: true},
+					},
+				},
+			},
+		},
+		Objects: []client.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "config"}},
+		},
+	}
+
+	if err := Write(dir, bd); err != nil {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+
+	for _, fileName := range []string{
+		filepath.Join(ManifestsDirName, "test.clusterserviceversion.yaml"),
+		filepath.Join(ManifestsDirName, "example.com_foos.yaml"),
+		filepath.Join(ManifestsDirName, "config_v1_configmap.yaml"),
+	} {
+		if _, err := os.Stat(filepath.Join(dir, fileName)); err != nil {
+			t.Errorf("missing file %q: %v", fileName, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, MetadataDirName, AnnotationsFileName))
+	if err != nil {
+		t.Fatalf("cannot read the annotations: %v", err)
+	}
+	var got struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("cannot decode the annotations: %v", err)
+	}
+	if !reflect.DeepEqual(got.Annotations, bd.Annotations()) {
+		t.Errorf("annotations mismatch: got %v expected %v", got.Annotations, bd.Annotations())
+	}
+}
+
+func newClusterRoleBinding(name, roleName, saName string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: roleName},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: saName, Namespace: "foo"}},
+	}
+}
+
+func nestedNames(t *testing.T, obj *unstructured.Unstructured, key string, fields ...string) []string {
+	t.Helper()
+	items, _, err := unstructured.NestedSlice(obj.Object, fields...)
+	if err != nil {
+		t.Fatalf("cannot get %v: %v", fields, err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.(map[string]interface{})[key].(string))
+	}
+	return names
+}
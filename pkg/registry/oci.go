@@ -0,0 +1,212 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	MediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// AnnotationRefName records the original pullspec of the images in the layout index
+	AnnotationRefName = "org.opencontainers.image.ref.name"
+
+	ociLayoutFileName = "oci-layout"
+	ociIndexFileName  = "index.json"
+	ociLayoutVersion  = "1.0.0"
+)
+
+var digestRe = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// Descriptor is the subset of the OCI content descriptor we need
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type index struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Manifests     []Descriptor `json:"manifests"`
+}
+
+// manifestRefs holds the references to other content of both image manifests and indexes
+type manifestRefs struct {
+	MediaType string       `json:"mediaType,omitempty"`
+	Config    *Descriptor  `json:"config,omitempty"`
+	Layers    []Descriptor `json:"layers,omitempty"`
+	Manifests []Descriptor `json:"manifests,omitempty"`
+}
+
+// Layout is an OCI image layout directory. Images are listed in the index
+// with their original pullspec as AnnotationRefName.
+type Layout struct {
+	Dir   string
+	index index
+}
+
+// NewLayout initializes an empty layout in dir, which is created if missing.
+func NewLayout(dir string) (*Layout, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(map[string]string{"imageLayoutVersion": ociLayoutVersion})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ociLayoutFileName), data, 0644); err != nil {
+		return nil, err
+	}
+	return &Layout{
+		Dir: dir,
+		index: index{
+			SchemaVersion: 2,
+			MediaType:     MediaTypeOCIIndex,
+		},
+	}, nil
+}
+
+// IsLayout tells if dir holds a layout.
+func IsLayout(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ociIndexFileName))
+	return err == nil
+}
+
+// OpenLayout reads the layout in dir.
+func OpenLayout(dir string) (*Layout, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ociIndexFileName))
+	if err != nil {
+		return nil, err
+	}
+	ly := Layout{Dir: dir}
+	if err := json.Unmarshal(data, &ly.index); err != nil {
+		return nil, fmt.Errorf("invalid OCI layout index: %w", err)
+	}
+	return &ly, nil
+}
+
+// Save writes the index, must be called once all the images are added.
+func (ly *Layout) Save() error {
+	data, err := json.Marshal(ly.index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(ly.Dir, ociIndexFileName), data, 0644)
+}
+
+// AddImage lists the manifest described by desc under the given pullspec.
+func (ly *Layout) AddImage(name string, desc Descriptor) {
+	desc.Annotations = map[string]string{AnnotationRefName: name}
+	ly.index.Manifests = append(ly.index.Manifests, desc)
+}
+
+// FindImage returns the descriptor of the image listed under the given pullspec.
+func (ly *Layout) FindImage(name string) (Descriptor, bool) {
+	for _, desc := range ly.index.Manifests {
+		if desc.Annotations[AnnotationRefName] == name {
+			return desc, true
+		}
+	}
+	return Descriptor{}, false
+}
+
+// Images returns the descriptors of the images, in the order they were added.
+func (ly *Layout) Images() []Descriptor {
+	return ly.index.Manifests
+}
+
+func (ly *Layout) blobPath(digest string) (string, error) {
+	if !digestRe.MatchString(digest) {
+		return "", fmt.Errorf("unsupported digest %q", digest)
+	}
+	return filepath.Join(ly.Dir, "blobs", "sha256", digest[len("sha256:"):]), nil
+}
+
+// HasBlob tells if the content with the given digest is already in the layout.
+func (ly *Layout) HasBlob(digest string) bool {
+	path, err := ly.blobPath(digest)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// WriteBlob stores the content read from r, which must match the digest and the size of desc.
+func (ly *Layout) WriteBlob(desc Descriptor, r io.Reader) error {
+	path, err := ly.blobPath(desc.Digest)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if got := "sha256:" + hex.EncodeToString(h.Sum(nil)); got != desc.Digest {
+		return fmt.Errorf("digest mismatch: got %q expected %q", got, desc.Digest)
+	}
+	if desc.Size > 0 && size != desc.Size {
+		return fmt.Errorf("size mismatch for %q: got %d expected %d", desc.Digest, size, desc.Size)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// OpenBlob returns the content with the given digest. The caller must close it.
+func (ly *Layout) OpenBlob(digest string) (*os.File, error) {
+	path, err := ly.blobPath(digest)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// ReadBlob returns the content with the given digest, meant for the small blobs like manifests.
+func (ly *Layout) ReadBlob(digest string) ([]byte, error) {
+	path, err := ly.blobPath(digest)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
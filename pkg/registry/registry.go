@@ -0,0 +1,377 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+// Package registry implements a minimal client of the distribution (v2) API
+// and the OCI image layout, to copy the images around without external tools.
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+)
+
+// maxManifestSize caps the manifests we read in memory
+const maxManifestSize = 4 << 20
+
+var manifestMediaTypes = []string{
+	MediaTypeOCIManifest,
+	MediaTypeOCIIndex,
+	MediaTypeDockerManifest,
+	MediaTypeDockerList,
+}
+
+// DockerHubHost serves the images which don't name a registry
+const DockerHubHost = "registry-1.docker.io"
+
+// ErrNotFound is returned, wrapped, when the requested content doesn't exist
+var ErrNotFound = errors.New("not found")
+
+var challengeParamRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Registry is a minimal client of the distribution (v2) API, enough to resolve digests
+// and to copy images between a registry and a Layout. Only anonymous access is supported,
+// including the token flow of the public registries.
+type Registry struct {
+	Host      string
+	PlainHTTP bool
+	Client    *http.Client
+}
+
+// ForImage returns the registry serving the image, the repository and the reference of the image
+// within it, applying the docker defaults for the images which don't name a registry.
+func ForImage(image string) (Registry, string, string) {
+	host, repo, ref := images.SplitImage(image)
+	if host == "" || host == "docker.io" {
+		host = DockerHubHost
+		if !strings.Contains(repo, "/") {
+			repo = "library/" + repo
+		}
+	}
+	return Registry{Host: host}, repo, ref
+}
+
+// Resolve returns the digest of the manifest the reference points to.
+func (reg Registry) Resolve(ctx context.Context, repo, ref string) (string, error) {
+	if digestRe.MatchString(ref) {
+		return ref, nil
+	}
+	headers := map[string]string{"Accept": strings.Join(manifestMediaTypes, ", ")}
+	resp, err := reg.do(ctx, http.MethodHead, reg.url(repo, "manifests", ref), nil, 0, headers)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); resp.StatusCode == http.StatusOK && digestRe.MatchString(digest) {
+		return digest, nil
+	}
+	// the digest header is optional, fall back to hash the manifest ourselves
+	data, _, err := reg.GetManifest(ctx, repo, ref)
+	if err != nil {
+		return "", err
+	}
+	return digestOf(data), nil
+}
+
+// Pull copies the image, manifests and blobs, from the registry into the layout.
+// Returns the descriptor of the top-level manifest, which is not added to the layout index.
+func (reg Registry) Pull(ctx context.Context, repo, ref string, ly *Layout) (Descriptor, error) {
+	data, mediaType, err := reg.GetManifest(ctx, repo, ref)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	desc := Descriptor{
+		MediaType: mediaType,
+		Digest:    digestOf(data),
+		Size:      int64(len(data)),
+	}
+	if strings.HasPrefix(ref, "sha256:") && ref != desc.Digest {
+		return desc, fmt.Errorf("manifest %s@%s: digest mismatch: got %q", repo, ref, desc.Digest)
+	}
+
+	refs, err := parseManifest(data)
+	if err != nil {
+		return desc, fmt.Errorf("manifest %s:%s: %w", repo, ref, err)
+	}
+	if desc.MediaType == "" {
+		desc.MediaType = refs.MediaType
+	}
+	for _, child := range refs.Manifests {
+		if _, err := reg.Pull(ctx, repo, child.Digest, ly); err != nil {
+			return desc, err
+		}
+	}
+	for _, blob := range refs.blobs() {
+		if ly.HasBlob(blob.Digest) {
+			continue
+		}
+		if err := reg.pullBlob(ctx, repo, blob, ly); err != nil {
+			return desc, err
+		}
+	}
+	return desc, ly.WriteBlob(desc, bytes.NewReader(data))
+}
+
+// Push copies the image described by desc from the layout into the registry, tagging it as ref.
+func (reg Registry) Push(ctx context.Context, ly *Layout, desc Descriptor, repo, ref string) error {
+	data, err := ly.ReadBlob(desc.Digest)
+	if err != nil {
+		return err
+	}
+	refs, err := parseManifest(data)
+	if err != nil {
+		return fmt.Errorf("manifest %q: %w", desc.Digest, err)
+	}
+	for _, child := range refs.Manifests {
+		if err := reg.Push(ctx, ly, child, repo, child.Digest); err != nil {
+			return err
+		}
+	}
+	for _, blob := range refs.blobs() {
+		if err := reg.pushBlob(ctx, ly, repo, blob); err != nil {
+			return err
+		}
+	}
+	mediaType := desc.MediaType
+	if mediaType == "" {
+		mediaType = refs.MediaType
+	}
+	resp, err := reg.do(ctx, http.MethodPut, reg.url(repo, "manifests", ref), bytes.NewReader(data), int64(len(data)), map[string]string{"Content-Type": mediaType})
+	if err != nil {
+		return err
+	}
+	return expectStatus(resp, http.StatusCreated)
+}
+
+// GetManifest returns the manifest the reference points to, and its media type.
+func (reg Registry) GetManifest(ctx context.Context, repo, ref string) ([]byte, string, error) {
+	headers := map[string]string{"Accept": strings.Join(manifestMediaTypes, ", ")}
+	resp, err := reg.do(ctx, http.MethodGet, reg.url(repo, "manifests", ref), nil, 0, headers)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", statusError(resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return data, mediaType, nil
+}
+
+// GetBlob returns the content of the small blob described by desc, verifying its digest.
+func (reg Registry) GetBlob(ctx context.Context, repo string, desc Descriptor) ([]byte, error) {
+	resp, err := reg.do(ctx, http.MethodGet, reg.url(repo, "blobs", desc.Digest), nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, err
+	}
+	if got := digestOf(data); got != desc.Digest {
+		return nil, fmt.Errorf("blob %s@%s: digest mismatch: got %q", repo, desc.Digest, got)
+	}
+	return data, nil
+}
+
+func (reg Registry) pullBlob(ctx context.Context, repo string, blob Descriptor, ly *Layout) error {
+	resp, err := reg.do(ctx, http.MethodGet, reg.url(repo, "blobs", blob.Digest), nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	return ly.WriteBlob(blob, resp.Body)
+}
+
+func (reg Registry) pushBlob(ctx context.Context, ly *Layout, repo string, blob Descriptor) error {
+	resp, err := reg.do(ctx, http.MethodHead, reg.url(repo, "blobs", blob.Digest), nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil // already there
+	}
+
+	resp, err = reg.do(ctx, http.MethodPost, reg.url(repo, "blobs", "uploads/"), nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	if err := expectStatus(resp, http.StatusAccepted); err != nil {
+		return err
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location: %w", err)
+	}
+	query := location.Query()
+	query.Set("digest", blob.Digest)
+	location.RawQuery = query.Encode()
+
+	fh, err := ly.OpenBlob(blob.Digest)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	st, err := fh.Stat()
+	if err != nil {
+		return err
+	}
+	resp, err = reg.do(ctx, http.MethodPut, location.String(), fh, st.Size(), map[string]string{"Content-Type": "application/octet-stream"})
+	if err != nil {
+		return err
+	}
+	return expectStatus(resp, http.StatusCreated)
+}
+
+func (reg Registry) url(repo, kind, ref string) string {
+	scheme := "https"
+	if reg.PlainHTTP {
+		scheme = "http"
+	}
+	return (&url.URL{Scheme: scheme, Host: reg.Host, Path: "/v2/" + repo + "/" + kind + "/" + ref}).String()
+}
+
+func (reg Registry) do(ctx context.Context, method, target string, body io.Reader, size int64, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+	cli := reg.Client
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	resp, err := cli.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || body != nil {
+		return resp, err
+	}
+
+	// anonymous token flow, we can retry only requests without body
+	token, err := reg.getToken(ctx, resp.Header.Get("WWW-Authenticate"))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return cli.Do(req)
+}
+
+func (reg Registry) getToken(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication %q, only anonymous access is supported", scheme)
+	}
+	var realm string
+	query := url.Values{}
+	for _, match := range challengeParamRe.FindAllStringSubmatch(params, -1) {
+		if match[1] == "realm" {
+			realm = match[2]
+		} else {
+			query.Set(match[1], match[2])
+		}
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil || realm == "" {
+		return "", fmt.Errorf("invalid authentication realm %q", realm)
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	cli := reg.Client
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+	var tr struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+	if tr.Token != "" {
+		return tr.Token, nil
+	}
+	return tr.AccessToken, nil
+}
+
+func expectStatus(resp *http.Response, code int) error {
+	defer resp.Body.Close()
+	if resp.StatusCode != code {
+		return statusError(resp)
+	}
+	return nil
+}
+
+func statusError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %w", resp.Request.Method, resp.Request.URL.Redacted(), ErrNotFound)
+	}
+	return fmt.Errorf("%s %s: unexpected status %q: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func parseManifest(data []byte) (manifestRefs, error) {
+	var refs manifestRefs
+	err := json.Unmarshal(data, &refs)
+	return refs, err
+}
+
+func (refs manifestRefs) blobs() []Descriptor {
+	var blobs []Descriptor
+	if refs.Config != nil {
+		blobs = append(blobs, *refs.Config)
+	}
+	return append(blobs, refs.Layers...)
+}
@@ -0,0 +1,307 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+)
+
+func TestForImage(t *testing.T) {
+	type testCase struct {
+		image        string
+		expectedHost string
+		expectedRepo string
+		expectedRef  string
+	}
+
+	testCases := []testCase{
+		{
+			image:        "quay.io/tas/rte:v1",
+			expectedHost: "quay.io",
+			expectedRepo: "tas/rte",
+			expectedRef:  "v1",
+		},
+		{
+			image:        "docker.io/library/busybox:1.36",
+			expectedHost: DockerHubHost,
+			expectedRepo: "library/busybox",
+			expectedRef:  "1.36",
+		},
+		{
+			image:        "busybox",
+			expectedHost: DockerHubHost,
+			expectedRepo: "library/busybox",
+			expectedRef:  "latest",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			reg, repo, ref := ForImage(tc.image)
+			if reg.Host != tc.expectedHost || repo != tc.expectedRepo || ref != tc.expectedRef {
+				t.Errorf("got %q %q %q expected %q %q %q", reg.Host, repo, ref, tc.expectedHost, tc.expectedRepo, tc.expectedRef)
+			}
+		})
+	}
+}
+
+func TestPullPush(t *testing.T) {
+	src := newFakeRegistry()
+	config := src.addBlob("tas/pause", []byte(`{"architecture":"amd64"}`))
+	layer := src.addBlob("tas/pause", []byte("layer data"))
+	manifest := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":%q,"size":24},"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar+gzip","digest":%q,"size":10}]}`, MediaTypeOCIManifest, config, layer)
+	src.addManifest("tas/pause", "3.9", []byte(manifest))
+	srcSrv := httptest.NewServer(src)
+	defer srcSrv.Close()
+
+	ly, err := NewLayout(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create the layout: %v", err)
+	}
+	ctx := context.Background()
+	srcReg := Registry{Host: strings.TrimPrefix(srcSrv.URL, "http://"), PlainHTTP: true}
+	desc, err := srcReg.Pull(ctx, "tas/pause", "3.9", ly)
+	if err != nil {
+		t.Fatalf("pull failed: %v", err)
+	}
+	if desc.Digest != digestOf([]byte(manifest)) || desc.MediaType != MediaTypeOCIManifest {
+		t.Errorf("unexpected descriptor: %+v", desc)
+	}
+	for _, digest := range []string{config, layer, desc.Digest} {
+		if !ly.HasBlob(digest) {
+			t.Errorf("blob %q missing in the layout", digest)
+		}
+	}
+	ly.AddImage("registry.k8s.io/pause:3.9", desc)
+	if err := ly.Save(); err != nil {
+		t.Fatalf("cannot save the layout: %v", err)
+	}
+
+	ly, err = OpenLayout(ly.Dir)
+	if err != nil {
+		t.Fatalf("cannot open the layout: %v", err)
+	}
+	imgs := ly.Images()
+	if len(imgs) != 1 || imgs[0].Annotations[AnnotationRefName] != "registry.k8s.io/pause:3.9" {
+		t.Fatalf("unexpected layout images: %+v", imgs)
+	}
+
+	dst := newFakeRegistry()
+	dstSrv := httptest.NewServer(dst)
+	defer dstSrv.Close()
+	dstReg := Registry{Host: strings.TrimPrefix(dstSrv.URL, "http://"), PlainHTTP: true}
+	if err := dstReg.Push(ctx, ly, imgs[0], "internal/pause", "3.9"); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if got := string(dst.manifests["internal/pause:3.9"]); got != manifest {
+		t.Errorf("pushed manifest %q expected %q", got, manifest)
+	}
+	if got := string(dst.blobs["internal/pause@"+layer]); got != "layer data" {
+		t.Errorf("pushed layer %q", got)
+	}
+}
+
+func TestPullDigestMismatch(t *testing.T) {
+	src := newFakeRegistry()
+	src.addManifest("tas/pause", "sha256:0000000000000000000000000000000000000000000000000000000000000000", []byte(`{"schemaVersion":2}`))
+	srv := httptest.NewServer(src)
+	defer srv.Close()
+
+	ly, err := NewLayout(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create the layout: %v", err)
+	}
+	reg := Registry{Host: strings.TrimPrefix(srv.URL, "http://"), PlainHTTP: true}
+	if _, err := reg.Pull(context.Background(), "tas/pause", "sha256:0000000000000000000000000000000000000000000000000000000000000000", ly); err == nil {
+		t.Errorf("tampered manifest accepted")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2}`)
+	fr := newFakeRegistry()
+	fr.addManifest("tas/rte", "v1", manifest)
+	fr.token = "secret"
+	srv := httptest.NewServer(fr)
+	defer srv.Close()
+
+	reg := Registry{Host: strings.TrimPrefix(srv.URL, "http://"), PlainHTTP: true}
+	digest, err := reg.Resolve(context.Background(), "tas/rte", "v1")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if digest != digestOf(manifest) {
+		t.Errorf("got digest %q expected %q", digest, digestOf(manifest))
+	}
+	if _, err := reg.Resolve(context.Background(), "tas/rte", "v2"); err == nil {
+		t.Errorf("missing tag resolved")
+	}
+}
+
+func TestResolveImageSet(t *testing.T) {
+	schedManifest := []byte(`{"schemaVersion":2,"config":{"digest":"sched"}}`)
+	pauseManifest := []byte(`{"schemaVersion":2,"config":{"digest":"pause"}}`)
+	fr := newFakeRegistry()
+	fr.addManifest("mirror/tas/sched", "v1", schedManifest)
+	fr.addManifest("mirror/pause", "3.9", pauseManifest)
+	srv := httptest.NewServer(fr)
+	defer srv.Close()
+
+	var is images.ImageSet
+	is.Set(images.KeyScheduler, "quay.io/tas/sched:v1", images.SourceFlag)
+	is.Set(images.KeyPause, "registry.k8s.io/pause:3.9", images.SourceDefault)
+
+	got, err := ResolveImageSet(context.Background(), logr.Discard(), is, srv.URL+"/mirror")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+
+	expectedSched := "quay.io/tas/sched@" + digestOf(schedManifest)
+	if image, src := got.Get(images.KeyScheduler); image != expectedSched || src != images.SourceFlag {
+		t.Errorf("got scheduler %q from %q expected %q", image, src, expectedSched)
+	}
+	expectedOrigins := map[string]string{
+		expectedSched: "quay.io/tas/sched:v1",
+		"registry.k8s.io/pause@" + digestOf(pauseManifest): "registry.k8s.io/pause:3.9",
+	}
+	if origins := got.Origins(); !reflect.DeepEqual(origins, expectedOrigins) {
+		t.Errorf("got origins %v expected %v", origins, expectedOrigins)
+	}
+	if image, _ := is.Get(images.KeyScheduler); image != "quay.io/tas/sched:v1" {
+		t.Errorf("input image set modified: %q", image)
+	}
+}
+
+// fakeRegistry implements the subset of the distribution API the Registry client uses
+type fakeRegistry struct {
+	lock      sync.Mutex
+	manifests map[string][]byte // repo:ref
+	blobs     map[string][]byte // repo@digest
+	uploads   int
+	// token, if set, must be requested through the anonymous token flow
+	token string
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		manifests: make(map[string][]byte),
+		blobs:     make(map[string][]byte),
+	}
+}
+
+func (fr *fakeRegistry) addBlob(repo string, data []byte) string {
+	digest := digestOf(data)
+	fr.blobs[repo+"@"+digest] = data
+	return digest
+}
+
+func (fr *fakeRegistry) addManifest(repo, ref string, data []byte) {
+	fr.manifests[repo+":"+ref] = data
+}
+
+func (fr *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+
+	if fr.token != "" {
+		if r.URL.Path == "/token" {
+			fmt.Fprintf(w, `{"token":%q}`, fr.token)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+fr.token {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="fake"`, r.Host))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	if repo, ref, ok := strings.Cut(path, "/manifests/"); ok {
+		key := repo + ":" + ref
+		switch r.Method {
+		case http.MethodGet:
+			data, found := fr.manifests[key]
+			if !found {
+				http.NotFound(w, r)
+				return
+			}
+			var refs manifestRefs
+			json.Unmarshal(data, &refs)
+			w.Header().Set("Content-Type", refs.MediaType)
+			w.Write(data)
+		case http.MethodHead:
+			data, found := fr.manifests[key]
+			if !found {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digestOf(data))
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			fr.manifests[key] = data
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	if repo, _, ok := strings.Cut(path, "/blobs/uploads/"); ok {
+		switch r.Method {
+		case http.MethodPost:
+			fr.uploads++
+			w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d?state=foo", repo, fr.uploads))
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodPut:
+			digest := r.URL.Query().Get("digest")
+			data, _ := io.ReadAll(r.Body)
+			if r.URL.Query().Get("state") != "foo" || digestOf(data) != digest {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fr.blobs[repo+"@"+digest] = data
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	if repo, digest, ok := strings.Cut(path, "/blobs/"); ok {
+		data, found := fr.blobs[repo+"@"+digest]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+		return
+	}
+	http.NotFound(w, r)
+}
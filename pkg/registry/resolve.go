@@ -0,0 +1,58 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+)
+
+// ResolveImageSet pins all the images of the set to the digests they currently point to.
+// If mirror is not empty, the digests are resolved querying the mirror (host[:port][/path],
+// prefixed by http:// to use plain HTTP) instead of the registry of each image.
+func ResolveImageSet(ctx context.Context, lh logr.Logger, is images.ImageSet, mirror string) (images.ImageSet, error) {
+	plainHTTP := strings.HasPrefix(mirror, "http://")
+	mirror = strings.TrimPrefix(strings.TrimPrefix(mirror, "http://"), "https://")
+
+	ret := images.ImageSet{}.Merge(is)
+	for _, key := range images.Keys() {
+		image, _ := ret.Get(key)
+		if image == "" {
+			continue
+		}
+		query := image
+		if mirror != "" {
+			query = images.MirrorImage(image, mirror)
+		}
+		reg, repo, ref := ForImage(query)
+		reg.PlainHTTP = plainHTTP
+		digest, err := reg.Resolve(ctx, repo, ref)
+		if err != nil {
+			return is, fmt.Errorf("cannot resolve image %q: %w", image, err)
+		}
+		if err := ret.Pin(key, digest); err != nil {
+			return is, err
+		}
+		lh.Info("resolved image", "image", image, "digest", digest, "registry", reg.Host)
+	}
+	return ret, nil
+}
@@ -0,0 +1,222 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+// Package signature verifies the cosign-style signatures of the images against a public key,
+// fully offline: the images and their signatures are read from a local registry or an OCI layout.
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/registry"
+)
+
+const (
+	// MediaTypeSimpleSigning is the media type of the signature payloads
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// AnnotationSignature holds the base64 encoded signature of the payload layer
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+)
+
+type Status string
+
+const (
+	StatusVerified Status = "verified"
+	StatusUnsigned Status = "unsigned"
+	StatusMismatch Status = "mismatch"
+	StatusError    Status = "error"
+)
+
+type Result struct {
+	Image  string
+	Digest string
+	Status Status
+	Reason string
+}
+
+type Report []Result
+
+// Err returns an error listing all the images not verified, or nil if all are.
+func (rep Report) Err() error {
+	var lines []string
+	for _, res := range rep {
+		if res.Status == StatusVerified {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s (%s)", res.Image, res.Status, res.Reason))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("image signature verification failed for %d of %d images:\n%s", len(lines), len(rep), strings.Join(lines, "\n"))
+}
+
+// Source gives access to the images and their signatures
+type Source interface {
+	// Resolve returns the digest of the manifest of the image.
+	Resolve(ctx context.Context, image string) (string, error)
+	// GetManifest returns the manifest with the given reference (tag or digest) in the repository of the image.
+	// Returns a registry.ErrNotFound error if missing.
+	GetManifest(ctx context.Context, image, ref string) ([]byte, error)
+	// GetBlob returns the blob described by desc in the repository of the image.
+	GetBlob(ctx context.Context, image string, desc registry.Descriptor) ([]byte, error)
+}
+
+// SignatureTag returns the tag cosign stores the signatures of the manifest with the given digest under.
+func SignatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// LoadPublicKey reads a PEM encoded public key. ECDSA, RSA and Ed25519 keys are supported.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%q: no PEM encoded public key found", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", path, err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%q: unsupported public key type %T", path, key)
+	}
+}
+
+// Verify checks every image has at least a signature made with the key for its current digest.
+func Verify(ctx context.Context, src Source, key crypto.PublicKey, imageList []string) Report {
+	var rep Report
+	seen := make(map[string]bool)
+	for _, image := range imageList {
+		if seen[image] {
+			continue
+		}
+		seen[image] = true
+		rep = append(rep, verifyImage(ctx, src, key, image))
+	}
+	return rep
+}
+
+type simpleSigning struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+func verifyImage(ctx context.Context, src Source, key crypto.PublicKey, image string) Result {
+	res := Result{Image: image}
+	digest, err := src.Resolve(ctx, image)
+	if err != nil {
+		res.Status, res.Reason = StatusError, err.Error()
+		return res
+	}
+	res.Digest = digest
+
+	data, err := src.GetManifest(ctx, image, SignatureTag(digest))
+	if errors.Is(err, registry.ErrNotFound) {
+		res.Status, res.Reason = StatusUnsigned, "no signature found"
+		return res
+	}
+	if err != nil {
+		res.Status, res.Reason = StatusError, err.Error()
+		return res
+	}
+	var sigs struct {
+		Layers []registry.Descriptor `json:"layers"`
+	}
+	if err := json.Unmarshal(data, &sigs); err != nil {
+		res.Status, res.Reason = StatusError, fmt.Sprintf("invalid signature manifest: %v", err)
+		return res
+	}
+
+	res.Status, res.Reason = StatusUnsigned, "no signature found"
+	for _, layer := range sigs.Layers {
+		if layer.MediaType != MediaTypeSimpleSigning {
+			continue
+		}
+		err := verifyLayer(ctx, src, key, image, digest, layer)
+		if err == nil {
+			res.Status, res.Reason = StatusVerified, ""
+			return res
+		}
+		res.Status, res.Reason = StatusMismatch, err.Error()
+	}
+	return res
+}
+
+func verifyLayer(ctx context.Context, src Source, key crypto.PublicKey, image, digest string, layer registry.Descriptor) error {
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[AnnotationSignature])
+	if err != nil || len(sig) == 0 {
+		return fmt.Errorf("invalid signature annotation")
+	}
+	payload, err := src.GetBlob(ctx, image, layer)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(key, payload, sig); err != nil {
+		return err
+	}
+	var ss simpleSigning
+	if err := json.Unmarshal(payload, &ss); err != nil {
+		return fmt.Errorf("invalid signature payload: %w", err)
+	}
+	if ss.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("signature is for digest %q", ss.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
+
+func verifySignature(key crypto.PublicKey, payload, sig []byte) error {
+	hash := sha256.Sum256(payload)
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(pub, hash[:], sig) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) == nil {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(pub, payload, sig) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return fmt.Errorf("signature doesn't match the key")
+}
@@ -0,0 +1,188 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/registry"
+)
+
+const testImage = "quay.io/tas/rte:v1"
+
+func TestVerifyLayout(t *testing.T) {
+	signKey := newTestKey(t)
+	otherKey := newTestKey(t)
+
+	type testCase struct {
+		name           string
+		signed         bool
+		signKey        *ecdsa.PrivateKey
+		signedDigest   string
+		expectedStatus Status
+	}
+
+	testCases := []testCase{
+		{
+			name:           "verified",
+			signed:         true,
+			signKey:        signKey,
+			expectedStatus: StatusVerified,
+		},
+		{
+			name:           "unsigned",
+			expectedStatus: StatusUnsigned,
+		},
+		{
+			name:           "other key",
+			signed:         true,
+			signKey:        otherKey,
+			expectedStatus: StatusMismatch,
+		},
+		{
+			name:           "other digest",
+			signed:         true,
+			signKey:        signKey,
+			signedDigest:   "sha256:" + strings.Repeat("0", 64),
+			expectedStatus: StatusMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			ly, err := registry.NewLayout(dir)
+			if err != nil {
+				t.Fatalf("cannot create the layout: %v", err)
+			}
+			desc := addBlob(t, ly, []byte(`{"schemaVersion":2}`), registry.MediaTypeOCIManifest, nil)
+			ly.AddImage(testImage, desc)
+			if tc.signed {
+				signedDigest := desc.Digest
+				if tc.signedDigest != "" {
+					signedDigest = tc.signedDigest
+				}
+				sigDesc := addSignature(t, ly, tc.signKey, signedDigest)
+				ly.AddImage("quay.io/tas/rte:"+SignatureTag(desc.Digest), sigDesc)
+			}
+			if err := ly.Save(); err != nil {
+				t.Fatalf("cannot save the layout: %v", err)
+			}
+
+			src, err := NewLayoutSource(dir)
+			if err != nil {
+				t.Fatalf("cannot open the layout: %v", err)
+			}
+			rep := Verify(context.Background(), src, &signKey.PublicKey, []string{testImage, testImage})
+			if len(rep) != 1 {
+				t.Fatalf("unexpected report: %+v", rep)
+			}
+			if rep[0].Status != tc.expectedStatus || rep[0].Digest != desc.Digest {
+				t.Errorf("got %+v expected status %q", rep[0], tc.expectedStatus)
+			}
+			if err := rep.Err(); (err == nil) != (tc.expectedStatus == StatusVerified) {
+				t.Errorf("unexpected report error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	key := newTestKey(t)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("cannot marshal the key: %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cosign.pub")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatalf("cannot write the key: %v", err)
+	}
+	got, err := LoadPublicKey(path)
+	if err != nil {
+		t.Fatalf("cannot load the key: %v", err)
+	}
+	if !key.PublicKey.Equal(got) {
+		t.Errorf("loaded a different key")
+	}
+
+	garbage := filepath.Join(dir, "garbage.pub")
+	if err := os.WriteFile(garbage, []byte("foobar"), 0644); err != nil {
+		t.Fatalf("cannot write the key: %v", err)
+	}
+	if _, err := LoadPublicKey(garbage); err == nil {
+		t.Errorf("invalid key loaded")
+	}
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate the key: %v", err)
+	}
+	return key
+}
+
+func addSignature(t *testing.T, ly *registry.Layout, key *ecdsa.PrivateKey, digest string) registry.Descriptor {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"quay.io/tas/rte"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("cannot sign: %v", err)
+	}
+	layer := addBlob(t, ly, payload, MediaTypeSimpleSigning, map[string]string{
+		AnnotationSignature: base64.StdEncoding.EncodeToString(sig),
+	})
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     registry.MediaTypeOCIManifest,
+		"layers":        []registry.Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal the signature manifest: %v", err)
+	}
+	return addBlob(t, ly, manifest, registry.MediaTypeOCIManifest, nil)
+}
+
+func addBlob(t *testing.T, ly *registry.Layout, data []byte, mediaType string, annotations map[string]string) registry.Descriptor {
+	sum := sha256.Sum256(data)
+	desc := registry.Descriptor{
+		MediaType:   mediaType,
+		Digest:      "sha256:" + hex.EncodeToString(sum[:]),
+		Size:        int64(len(data)),
+		Annotations: annotations,
+	}
+	if err := ly.WriteBlob(desc, bytes.NewReader(data)); err != nil {
+		t.Fatalf("cannot write the blob: %v", err)
+	}
+	return desc
+}
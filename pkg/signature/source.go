@@ -0,0 +1,98 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ */
+
+package signature
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/k8stopologyawareschedwg/deployer/pkg/images"
+	"github.com/k8stopologyawareschedwg/deployer/pkg/registry"
+)
+
+type registrySource struct {
+	mirror    string
+	plainHTTP bool
+}
+
+// NewRegistrySource reads the images from the mirror (host[:port][/path], prefixed
+// by http:// to use plain HTTP), which mirrors their original repositories.
+func NewRegistrySource(mirror string) Source {
+	return registrySource{
+		mirror:    strings.TrimPrefix(strings.TrimPrefix(mirror, "http://"), "https://"),
+		plainHTTP: strings.HasPrefix(mirror, "http://"),
+	}
+}
+
+func (rs registrySource) locate(image string) (registry.Registry, string, string) {
+	reg, repo, ref := registry.ForImage(images.MirrorImage(image, rs.mirror))
+	reg.PlainHTTP = rs.plainHTTP
+	return reg, repo, ref
+}
+
+func (rs registrySource) Resolve(ctx context.Context, image string) (string, error) {
+	reg, repo, ref := rs.locate(image)
+	return reg.Resolve(ctx, repo, ref)
+}
+
+func (rs registrySource) GetManifest(ctx context.Context, image, ref string) ([]byte, error) {
+	reg, repo, _ := rs.locate(image)
+	data, _, err := reg.GetManifest(ctx, repo, ref)
+	return data, err
+}
+
+func (rs registrySource) GetBlob(ctx context.Context, image string, desc registry.Descriptor) ([]byte, error) {
+	reg, repo, _ := rs.locate(image)
+	return reg.GetBlob(ctx, repo, desc)
+}
+
+type layoutSource struct {
+	ly *registry.Layout
+}
+
+// NewLayoutSource reads the images from the OCI layout in dir, in which they are listed
+// by their pullspec, and their signatures as repository:SignatureTag, like in the bundles.
+func NewLayoutSource(dir string) (Source, error) {
+	ly, err := registry.OpenLayout(dir)
+	if err != nil {
+		return nil, err
+	}
+	return layoutSource{ly: ly}, nil
+}
+
+func (ls layoutSource) Resolve(ctx context.Context, image string) (string, error) {
+	if desc, ok := ls.ly.FindImage(image); ok {
+		return desc.Digest, nil
+	}
+	if _, _, ref := images.SplitImage(image); strings.HasPrefix(ref, "sha256:") && ls.ly.HasBlob(ref) {
+		return ref, nil
+	}
+	return "", fmt.Errorf("image not found in the layout: %w", registry.ErrNotFound)
+}
+
+func (ls layoutSource) GetManifest(ctx context.Context, image, ref string) ([]byte, error) {
+	desc, ok := ls.ly.FindImage(images.Repository(image) + ":" + ref)
+	if !ok {
+		return nil, fmt.Errorf("%s:%s: %w", images.Repository(image), ref, registry.ErrNotFound)
+	}
+	return ls.ly.ReadBlob(desc.Digest)
+}
+
+func (ls layoutSource) GetBlob(ctx context.Context, image string, desc registry.Descriptor) ([]byte, error) {
+	return ls.ly.ReadBlob(desc.Digest)
+}